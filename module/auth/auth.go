@@ -0,0 +1,166 @@
+// Package auth authenticates requests with JWT bearer tokens.
+// Tokens are verified against the keys of a JWKS loaded from a file, a URL or
+// the issuer's OpenID Connect discovery document, refreshed periodically and
+// whenever a token is signed with an unknown key. The authgrpc and authgin
+// packages authenticate every call of the grpc and httpgin modules and put
+// the caller's Principal into the request context.
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/things-kit/module/errmap"
+	"go.uber.org/fx"
+	"google.golang.org/grpc/codes"
+)
+
+// Module provides the token Verifier to the application.
+var Module = fx.Module("auth",
+	fx.Provide(
+		NewConfig,
+		NewVerifier,
+	),
+)
+
+var (
+	// ErrMissingToken is returned when a request carries no bearer token.
+	ErrMissingToken = errors.New("auth: missing bearer token")
+	// ErrInvalidToken is returned for tokens that fail verification.
+	ErrInvalidToken = errors.New("auth: invalid token")
+)
+
+// Config holds the authentication configuration.
+type Config struct {
+	Issuer      string        `mapstructure:"issuer"`       // Required "iss"; also used for OIDC discovery
+	Audience    []string      `mapstructure:"audience"`     // Accepted "aud" values (empty = any)
+	Algorithms  []string      `mapstructure:"algorithms"`   // Accepted signing algorithms
+	Leeway      time.Duration `mapstructure:"leeway"`       // Clock skew tolerated on exp, nbf and iat
+	RolesClaim  string        `mapstructure:"roles_claim"`  // Claim holding roles; dots select nested claims
+	ScopesClaim string        `mapstructure:"scopes_claim"` // Claim holding scopes, as a space-separated string or a list
+	JWKS        JWKSConfig    `mapstructure:"jwks"`
+
+	// PublicMethods are gRPC methods served without authentication
+	PublicMethods Allowlist `mapstructure:"public_methods"`
+	// PublicRoutes are HTTP routes served without authentication
+	PublicRoutes Allowlist `mapstructure:"public_routes"`
+}
+
+// JWKSConfig holds the location and refresh settings of the signing keys.
+// Without a URL or a file, the keys are found through the OIDC discovery
+// document of the issuer.
+type JWKSConfig struct {
+	URL                string        `mapstructure:"url"`                  // JWKS endpoint
+	File               string        `mapstructure:"file"`                 // JWKS file, re-read on refresh
+	RefreshInterval    time.Duration `mapstructure:"refresh_interval"`     // Periodic refresh
+	MinRefreshInterval time.Duration `mapstructure:"min_refresh_interval"` // Minimum time between refreshes for unknown key IDs
+	Timeout            time.Duration `mapstructure:"timeout"`              // Timeout of discovery and JWKS requests
+}
+
+// NewConfig creates the authentication configuration from Viper.
+func NewConfig(v *viper.Viper) *Config {
+	cfg := &Config{
+		Algorithms:  []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"},
+		Leeway:      30 * time.Second,
+		RolesClaim:  "roles",
+		ScopesClaim: "scope",
+		JWKS: JWKSConfig{
+			RefreshInterval:    15 * time.Minute,
+			MinRefreshInterval: time.Minute,
+			Timeout:            5 * time.Second,
+		},
+		PublicMethods: Allowlist{"/grpc.health.v1.Health/*"},
+	}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("auth", cfg)
+	}
+
+	return cfg
+}
+
+// Allowlist matches gRPC full method names or HTTP routes. Entries match
+// exactly, or by prefix when they end with "*":
+//
+//	/grpc.health.v1.Health/*   every method of a gRPC service
+//	/healthz                   an HTTP route, any method
+//	GET /docs/*                HTTP GET on routes under /docs/
+type Allowlist []string
+
+// Match reports whether any entry matches one of the names.
+func (a Allowlist) Match(names ...string) bool {
+	for _, entry := range a {
+		prefix, wildcard := strings.CutSuffix(entry, "*")
+		for _, name := range names {
+			if name == entry || (wildcard && strings.HasPrefix(name, prefix)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Principal is the authenticated caller.
+type Principal struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	Roles     []string
+	Scopes    []string
+	ExpiresAt time.Time
+	Claims    map[string]any // All claims of the token
+}
+
+// HasRole reports whether the principal has the role.
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// HasScope reports whether the principal was granted the scope.
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal of an authenticated request.
+//
+// Example:
+//
+//	func (s *OrderService) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+//	    principal, _ := auth.PrincipalFrom(ctx)
+//	    return s.store.ListByOwner(ctx, principal.Subject)
+//	}
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// BearerToken extracts the token from an Authorization header value.
+func BearerToken(authorization string) (string, error) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", ErrMissingToken
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// Unauthenticated converts an authentication error into the error sent to
+// clients: codes.Unauthenticated (HTTP 401) without verification details.
+func Unauthenticated(err error) *errmap.Error {
+	message := "invalid token"
+	if errors.Is(err, ErrMissingToken) {
+		message = "missing bearer token"
+	}
+	return errmap.New(codes.Unauthenticated, message).Wrap(err)
+}
@@ -0,0 +1,58 @@
+// Package authgin authenticates the requests of the httpgin module with the auth Verifier.
+package authgin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/things-kit/module/auth"
+	"github.com/things-kit/module/httpgin"
+	"go.uber.org/fx"
+)
+
+// Module installs the Authentication middleware at httpgin.OrderAuth,
+// serving auth.public_routes without a token. It requires auth.Module.
+var Module = fx.Module("authgin",
+	httpgin.AsGinMiddleware(func(verifier *auth.Verifier) gin.HandlerFunc {
+		return Authentication(verifier, verifier.Config().PublicRoutes)
+	}, httpgin.OrderAuth),
+)
+
+// Authentication verifies the bearer token in the Authorization header of
+// every request and puts the caller's auth.Principal into the request
+// context. Routes matching public (by route pattern, or by method and route
+// pattern such as "GET /healthz") are served without a token. Requests
+// without a valid token get a 401 in the google.rpc.Status format of
+// httpgin.WriteStatus.
+//
+// Example:
+//
+//	func (h *OrderHandler) list(c *gin.Context) {
+//	    principal, _ := auth.PrincipalFrom(c.Request.Context())
+//	    orders, err := h.store.ListByOwner(c.Request.Context(), principal.Subject)
+//	    ...
+//	}
+func Authentication(verifier *auth.Verifier, public auth.Allowlist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route != "" && public.Match(route, c.Request.Method+" "+route) {
+			c.Next()
+			return
+		}
+
+		principal, err := verifier.Authenticate(c.Request.Context(), c.GetHeader("Authorization"))
+		if err != nil {
+			challenge := "Bearer"
+			if !errors.Is(err, auth.ErrMissingToken) {
+				challenge = `Bearer error="invalid_token"`
+			}
+			c.Header("WWW-Authenticate", challenge)
+			httpgin.WriteStatus(c, http.StatusUnauthorized, auth.Unauthenticated(err).GRPCStatus())
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
@@ -0,0 +1,95 @@
+package authgin_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/things-kit/module/auth"
+	"github.com/things-kit/module/auth/authgin"
+	"github.com/things-kit/module/auth/authtest"
+	"github.com/things-kit/module/httpgin"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+type meHandler struct{}
+
+func (meHandler) RegisterRoutes(engine *gin.Engine) {
+	engine.GET("/healthz", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	engine.GET("/me", func(c *gin.Context) {
+		principal, _ := auth.PrincipalFrom(c.Request.Context())
+		c.String(http.StatusOK, principal.Subject)
+	})
+}
+
+// TestAuthentication verifies bearer tokens, principals in context and public routes
+func TestAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	issuer := authtest.NewIssuer(t)
+	v := viper.New()
+	v.Set("http.port", 0)
+	v.Set("auth.public_routes", []string{"/healthz"})
+	issuer.Configure(v)
+
+	var server *httpgin.GinServer
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		httpgin.Module,
+		auth.Module,
+		authgin.Module,
+		httpgin.AsGinHandler(func() meHandler { return meHandler{} }),
+		fx.Populate(&server),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		code          int
+		body          string
+		challenge     string
+	}{
+		{name: "valid token", path: "/me", authorization: "Bearer " + issuer.Token(t, "user-1"), code: http.StatusOK, body: "user-1"},
+		{name: "missing token", path: "/me", code: http.StatusUnauthorized, challenge: "Bearer"},
+		{name: "invalid token", path: "/me", authorization: "Bearer nope", code: http.StatusUnauthorized, challenge: `Bearer error="invalid_token"`},
+		{name: "public route", path: "/healthz", code: http.StatusOK, body: "ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			server.Engine().ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+			assert.Equal(t, tt.challenge, w.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
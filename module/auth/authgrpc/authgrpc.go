@@ -0,0 +1,79 @@
+// Package authgrpc authenticates the calls of the grpc module with the auth Verifier.
+package authgrpc
+
+import (
+	"context"
+
+	"github.com/things-kit/module/auth"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Module chains the authentication interceptors at grpcmodule.OrderAuth,
+// serving auth.public_methods without a token. It requires auth.Module.
+var Module = fx.Module("authgrpc",
+	grpcmodule.AsUnaryInterceptor(func(verifier *auth.Verifier) grpc.UnaryServerInterceptor {
+		return UnaryServerInterceptor(verifier, verifier.Config().PublicMethods)
+	}, grpcmodule.OrderAuth),
+	grpcmodule.AsStreamInterceptor(func(verifier *auth.Verifier) grpc.StreamServerInterceptor {
+		return StreamServerInterceptor(verifier, verifier.Config().PublicMethods)
+	}, grpcmodule.OrderAuth),
+)
+
+// UnaryServerInterceptor verifies the bearer token in the "authorization"
+// metadata of every call and puts the caller's auth.Principal into the
+// handler's context. Methods matching public are served without a token.
+// Calls without a valid token fail with codes.Unauthenticated.
+func UnaryServerInterceptor(verifier *auth.Verifier, public auth.Allowlist) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if public.Match(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, err := authenticate(ctx, verifier)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the stream counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor(verifier *auth.Verifier, public auth.Allowlist) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if public.Match(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		ctx, err := authenticate(ss.Context(), verifier)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate verifies the bearer token of an incoming call.
+func authenticate(ctx context.Context, verifier *auth.Verifier) (context.Context, error) {
+	var authorization string
+	if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+		authorization = values[0]
+	}
+
+	principal, err := verifier.Authenticate(ctx, authorization)
+	if err != nil {
+		return ctx, auth.Unauthenticated(err)
+	}
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the overridden context.
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,85 @@
+package authgrpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/auth"
+	"github.com/things-kit/module/auth/authgrpc"
+	"github.com/things-kit/module/auth/authtest"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+// TestUnaryServerInterceptor verifies bearer tokens, principals in context and public methods
+func TestUnaryServerInterceptor(t *testing.T) {
+	issuer := authtest.NewIssuer(t)
+	v := viper.New()
+	issuer.Configure(v)
+
+	var verifier *auth.Verifier
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		auth.Module,
+		fx.Populate(&verifier),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	interceptor := authgrpc.UnaryServerInterceptor(verifier, verifier.Config().PublicMethods)
+	call := func(method, authorization string) (string, error) {
+		ctx := context.Background()
+		if authorization != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+		}
+		resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+			func(ctx context.Context, _ any) (any, error) {
+				if p, ok := auth.PrincipalFrom(ctx); ok {
+					return p.Subject, nil
+				}
+				return "anonymous", nil
+			})
+		if err != nil {
+			return "", err
+		}
+		return resp.(string), nil
+	}
+
+	subject, err := call("/orders.v1.Orders/Get", "Bearer "+issuer.Token(t, "user-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", subject)
+
+	_, err = call("/orders.v1.Orders/Get", "")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "missing bearer token", status.Convert(err).Message())
+
+	_, err = call("/orders.v1.Orders/Get", "Bearer not-a-token")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "invalid token", status.Convert(err).Message())
+
+	subject, err = call("/grpc.health.v1.Health/Check", "")
+	require.NoError(t, err)
+	assert.Equal(t, "anonymous", subject)
+}
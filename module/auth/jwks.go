@@ -0,0 +1,293 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/things-kit/module/log"
+)
+
+// keySet caches the public keys of a JWKS by key ID.
+type keySet struct {
+	cfg    JWKSConfig
+	issuer string
+	client *http.Client
+	logger log.Logger
+
+	url       string // JWKS URL, resolved through discovery when not configured
+	refreshMu sync.Mutex
+
+	mu      sync.RWMutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+func newKeySet(cfg JWKSConfig, issuer string, logger log.Logger) *keySet {
+	return &keySet{
+		cfg:    cfg,
+		issuer: issuer,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: logger,
+		url:    cfg.URL,
+	}
+}
+
+// key returns the key with the given ID, refreshing the set when the ID is
+// unknown (keys were rotated) and the last refresh is older than
+// min_refresh_interval. An empty ID selects the only key of the set.
+func (k *keySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	key, fetched, ok := k.lookup(kid)
+	if ok {
+		return key, nil
+	}
+	if time.Since(fetched) < k.cfg.MinRefreshInterval {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	if err := k.refresh(ctx, fetched); err != nil {
+		return nil, err
+	}
+	if key, _, ok = k.lookup(kid); !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return key, nil
+}
+
+func (k *keySet) lookup(kid string) (crypto.PublicKey, time.Time, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, k.fetched, true
+		}
+	}
+	key, ok := k.keys[kid]
+	return key, k.fetched, ok
+}
+
+// refresh reloads the set unless another caller refreshed it since seen.
+func (k *keySet) refresh(ctx context.Context, seen time.Time) error {
+	k.refreshMu.Lock()
+	defer k.refreshMu.Unlock()
+
+	k.mu.RLock()
+	fetched := k.fetched
+	k.mu.RUnlock()
+	if fetched.After(seen) {
+		return nil
+	}
+
+	keys, err := k.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.fetched = time.Now()
+	k.mu.Unlock()
+
+	k.logger.Debug("Loaded JWKS", log.Field{Key: "keys", Value: len(keys)})
+	return nil
+}
+
+// run refreshes the set every refresh_interval until ctx is done. Failed
+// refreshes are logged and the current keys are kept.
+func (k *keySet) run(ctx context.Context) {
+	if k.cfg.RefreshInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(k.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			k.mu.RLock()
+			fetched := k.fetched
+			k.mu.RUnlock()
+			if err := k.refresh(ctx, fetched); err != nil && ctx.Err() == nil {
+				k.logger.Error("Failed to refresh JWKS", err)
+			}
+		}
+	}
+}
+
+// load reads the JWKS from the configured file or URL.
+func (k *keySet) load(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	var data []byte
+	var err error
+	switch {
+	case k.cfg.File != "":
+		data, err = os.ReadFile(k.cfg.File)
+	default:
+		if k.url == "" {
+			if k.url, err = k.discover(ctx); err != nil {
+				return nil, err
+			}
+		}
+		data, err = k.get(ctx, k.url)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load JWKS: %w", err)
+	}
+	return parseJWKS(data)
+}
+
+// discover finds the JWKS URL in the OpenID Connect discovery document of the issuer.
+func (k *keySet) discover(ctx context.Context) (string, error) {
+	if k.issuer == "" {
+		return "", errors.New("auth: one of auth.jwks.url, auth.jwks.file or auth.issuer is required")
+	}
+
+	data, err := k.get(ctx, strings.TrimSuffix(k.issuer, "/")+"/.well-known/openid-configuration")
+	if err != nil {
+		return "", fmt.Errorf("OIDC discovery: %w", err)
+	}
+	var doc struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("OIDC discovery: %w", err)
+	}
+	if doc.JWKSURI == "" {
+		return "", errors.New("OIDC discovery: no jwks_uri")
+	}
+	return doc.JWKSURI, nil
+}
+
+func (k *keySet) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// jwk is a JSON Web Key (RFC 7517) holding a public key.
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS parses the signing keys of a JWKS. Encryption keys and key types
+// other than RSA, EC and Ed25519 are skipped.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("parse JWKS key %q: %w", k.Kid, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("parse JWKS: no signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
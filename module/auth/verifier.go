@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+)
+
+// VerifierParams contains the dependencies of NewVerifier.
+type VerifierParams struct {
+	fx.In
+	Lifecycle fx.Lifecycle
+	Logger    log.Logger
+	Config    *Config
+}
+
+// Verifier verifies bearer tokens and builds the Principal of their subject.
+type Verifier struct {
+	cfg    *Config
+	keys   *keySet
+	parser *jwt.Parser
+	logger log.Logger
+}
+
+// NewVerifier creates a Verifier. The keys are loaded on start, which fails
+// when they cannot be loaded, and refreshed in the background until stop.
+func NewVerifier(p VerifierParams) (*Verifier, error) {
+	cfg := p.Config
+	if cfg.JWKS.URL == "" && cfg.JWKS.File == "" && cfg.Issuer == "" {
+		return nil, errors.New("auth: one of auth.jwks.url, auth.jwks.file or auth.issuer is required")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(cfg.Algorithms),
+		jwt.WithLeeway(cfg.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	v := &Verifier{
+		cfg:    cfg,
+		keys:   newKeySet(cfg.JWKS, cfg.Issuer, p.Logger),
+		parser: jwt.NewParser(opts...),
+		logger: p.Logger,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(startCtx context.Context) error {
+			if err := v.keys.refresh(startCtx, time.Time{}); err != nil {
+				return err
+			}
+			go func() {
+				defer close(done)
+				v.keys.run(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+
+	return v, nil
+}
+
+// Config returns the authentication configuration.
+func (v *Verifier) Config() *Config {
+	return v.cfg
+}
+
+// Authenticate verifies the bearer token of an Authorization header value.
+func (v *Verifier) Authenticate(ctx context.Context, authorization string) (*Principal, error) {
+	token, err := BearerToken(authorization)
+	if err != nil {
+		return nil, err
+	}
+	return v.Verify(ctx, token)
+}
+
+// Verify verifies the signature, issuer, audience and lifetime of a token and
+// returns its principal. Errors wrap ErrInvalidToken.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.key(ctx, kid)
+	})
+	if err != nil {
+		v.logger.DebugC(ctx, "Token verification failed", log.Field{Key: "error", Value: err.Error()})
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	audience, _ := claims.GetAudience()
+	if len(v.cfg.Audience) > 0 && !slices.ContainsFunc(audience, func(aud string) bool {
+		return slices.Contains(v.cfg.Audience, aud)
+	}) {
+		return nil, fmt.Errorf("%w: audience %v not accepted", ErrInvalidToken, audience)
+	}
+
+	p := &Principal{
+		Audience: audience,
+		Roles:    stringsClaim(claims, v.cfg.RolesClaim),
+		Scopes:   stringsClaim(claims, v.cfg.ScopesClaim),
+		Claims:   claims,
+	}
+	p.Subject, _ = claims.GetSubject()
+	p.Issuer, _ = claims.GetIssuer()
+	if exp, _ := claims.GetExpirationTime(); exp != nil {
+		p.ExpiresAt = exp.Time
+	}
+	return p, nil
+}
+
+// stringsClaim reads a claim holding a list of strings or a space-separated
+// string. Dots in name select nested claims, e.g. "realm_access.roles".
+func stringsClaim(claims map[string]any, name string) []string {
+	if name == "" {
+		return nil
+	}
+
+	var value any = claims
+	for _, part := range strings.Split(name, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[part]
+	}
+
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		out := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package auth_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/auth"
+	"github.com/things-kit/module/auth/authtest"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+func newVerifier(t *testing.T, v *viper.Viper) *auth.Verifier {
+	var verifier *auth.Verifier
+	app := fxtest.New(t,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		auth.Module,
+		fx.Populate(&verifier),
+	)
+	app.RequireStart()
+	t.Cleanup(app.RequireStop)
+	return verifier
+}
+
+// TestVerify verifies tokens against keys found through OIDC discovery
+func TestVerify(t *testing.T) {
+	issuer := authtest.NewIssuer(t)
+	v := viper.New()
+	issuer.Configure(v)
+	v.Set("auth.audience", []string{"orders"})
+	v.Set("auth.jwks.min_refresh_interval", 0)
+	verifier := newVerifier(t, v)
+
+	valid := issuer.Token(t, "user-1",
+		authtest.WithAudience("orders"),
+		authtest.WithRoles("admin"),
+		authtest.WithScopes("orders:read", "orders:write"),
+	)
+
+	p, err := verifier.Authenticate(context.Background(), "Bearer "+valid)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", p.Subject)
+	assert.Equal(t, issuer.URL(), p.Issuer)
+	assert.True(t, p.HasRole("admin"))
+	assert.True(t, p.HasScope("orders:write"))
+	assert.WithinDuration(t, time.Now().Add(time.Hour), p.ExpiresAt, time.Minute)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "expired", token: issuer.Token(t, "user-1", authtest.WithAudience("orders"), authtest.WithExpiry(-time.Hour))},
+		{name: "wrong audience", token: issuer.Token(t, "user-1", authtest.WithAudience("billing"))},
+		{name: "wrong issuer", token: issuer.Token(t, "user-1", authtest.WithAudience("orders"), authtest.WithClaim("iss", "https://evil.example.com"))},
+		{name: "tampered", token: valid[:len(valid)-4] + "AAAA"},
+		{name: "signed by another issuer", token: authtest.NewIssuer(t).Token(t, "user-1", authtest.WithAudience("orders"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(context.Background(), tt.token)
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		})
+	}
+
+	_, err = verifier.Authenticate(context.Background(), "")
+	assert.ErrorIs(t, err, auth.ErrMissingToken)
+
+	// Tokens signed with a rotated key are accepted once the JWKS is refreshed
+	issuer.Rotate()
+	_, err = verifier.Verify(context.Background(), issuer.Token(t, "user-1", authtest.WithAudience("orders")))
+	assert.NoError(t, err)
+}
+
+// TestVerifyJWKSFile verifies tokens against keys read from a file
+func TestVerifyJWKSFile(t *testing.T) {
+	issuer := authtest.NewIssuer(t)
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(file, issuer.JWKS(), 0o600))
+
+	v := viper.New()
+	v.Set("auth.jwks.file", file)
+	v.Set("auth.roles_claim", "realm_access.roles")
+	verifier := newVerifier(t, v)
+
+	p, err := verifier.Verify(context.Background(), issuer.Token(t, "svc-1",
+		authtest.WithClaim("realm_access", map[string]any{"roles": []string{"reader"}})))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reader"}, p.Roles)
+}
+
+// TestAllowlist verifies exact and prefix matches
+func TestAllowlist(t *testing.T) {
+	allowlist := auth.Allowlist{"/grpc.health.v1.Health/*", "/healthz", "GET /docs/*"}
+
+	tests := []struct {
+		names []string
+		match bool
+	}{
+		{names: []string{"/grpc.health.v1.Health/Check"}, match: true},
+		{names: []string{"/orders.v1.Orders/Get"}, match: false},
+		{names: []string{"/healthz", "POST /healthz"}, match: true},
+		{names: []string{"/docs/index.html", "GET /docs/index.html"}, match: true},
+		{names: []string{"/docs/index.html", "DELETE /docs/index.html"}, match: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.match, allowlist.Match(tt.names...), tt.names)
+	}
+}
@@ -0,0 +1,142 @@
+// Package authz enforces authorization policies on authenticated requests.
+// gRPC methods and HTTP routes are mapped to the roles and scopes they
+// require; the authzgrpc and authzgin packages check every call of the grpc
+// and httpgin modules against the application's Engine, using the
+// auth.Principal of the request context.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+	"github.com/things-kit/module/auth"
+	"github.com/things-kit/module/errmap"
+	"go.uber.org/fx"
+	"google.golang.org/grpc/codes"
+)
+
+// Module provides the rule-based Engine, enforced by authzgrpc.Module and
+// authzgin.Module.
+var Module = fx.Module("authz",
+	fx.Provide(
+		NewConfig,
+		fx.Annotate(
+			NewRuleEngine,
+			fx.As(new(Engine)),
+		),
+	),
+)
+
+// Default policies for resources matching no rule.
+const (
+	DefaultAllow         = "allow"         // Everyone, including anonymous callers
+	DefaultAuthenticated = "authenticated" // Any authenticated principal
+	DefaultDeny          = "deny"          // No one
+)
+
+// Config holds the authorization configuration.
+type Config struct {
+	Default   string `mapstructure:"default"`    // Policy for resources matching no rule: allow, authenticated or deny
+	RulesFile string `mapstructure:"rules_file"` // YAML, JSON or TOML file with a "rules" list
+	Rules     []Rule `mapstructure:"rules"`
+}
+
+// NewConfig creates the authorization configuration from Viper.
+func NewConfig(v *viper.Viper) *Config {
+	cfg := &Config{
+		Default: DefaultAuthenticated,
+	}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("authz", cfg)
+	}
+
+	return cfg
+}
+
+// Rule grants access to resources. Resources are gRPC full method names or
+// HTTP route patterns, optionally prefixed with a method, matched like an
+// auth.Allowlist. A rule without roles or scopes only requires authentication.
+type Rule struct {
+	Resources auth.Allowlist `mapstructure:"resources"`
+	Roles     []string       `mapstructure:"roles"`  // The principal needs one of these roles
+	Scopes    []string       `mapstructure:"scopes"` // The principal needs all of these scopes
+	Public    bool           `mapstructure:"public"` // Allow anonymous callers
+	Deny      bool           `mapstructure:"deny"`   // Deny everyone
+}
+
+// AsRule registers a rule with the RuleEngine. Rules from configuration are
+// tried first, so operators can override registered rules.
+//
+// Example:
+//
+//	authz.AsRule(authz.Rule{
+//	    Resources: auth.Allowlist{"/orders.v1.Orders/DeleteOrder", "DELETE /orders/:id"},
+//	    Roles:     []string{"admin"},
+//	})
+func AsRule(rule Rule) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			func() Rule { return rule },
+			fx.ResultTags(`group:"authz.rules"`),
+		),
+	)
+}
+
+// Request describes a call to authorize.
+type Request struct {
+	Principal *auth.Principal // Nil for anonymous calls
+	Resource  string          // gRPC full method name or HTTP route pattern
+	Method    string          // HTTP method; empty for gRPC calls
+	Input     any             // gRPC request message or *http.Request, for attribute-based engines
+}
+
+// Decision is the outcome of an authorization check.
+type Decision struct {
+	Allowed bool
+	Reason  string // Why the call was denied; logged, never sent to clients
+}
+
+// Engine decides whether calls are allowed.
+//
+// Replace the RuleEngine to back policies with another engine, such as an
+// embedded policy language:
+//
+//	fx.Decorate(func(authz.Engine) authz.Engine {
+//	    return authz.EngineFunc(func(ctx context.Context, req authz.Request) (authz.Decision, error) {
+//	        return evalPolicy(ctx, req)
+//	    })
+//	})
+type Engine interface {
+	Authorize(ctx context.Context, req Request) (Decision, error)
+}
+
+// EngineFunc adapts a function to the Engine interface.
+type EngineFunc func(ctx context.Context, req Request) (Decision, error)
+
+// Authorize implements Engine.
+func (f EngineFunc) Authorize(ctx context.Context, req Request) (Decision, error) {
+	return f(ctx, req)
+}
+
+// Check authorizes req with engine and returns the error sent to clients when
+// the call is not allowed: codes.Unauthenticated for anonymous callers,
+// codes.PermissionDenied otherwise, and codes.Internal when the engine fails.
+func Check(ctx context.Context, engine Engine, req Request) error {
+	decision, err := engine.Authorize(ctx, req)
+	if err != nil {
+		return errmap.New(codes.Internal, "authorization failed").Wrap(fmt.Errorf("authz: %s: %w", req.Resource, err))
+	}
+	if decision.Allowed {
+		return nil
+	}
+
+	cause := errors.New(decision.Reason)
+	if req.Principal == nil {
+		return errmap.New(codes.Unauthenticated, "authentication required").Wrap(cause)
+	}
+	return errmap.New(codes.PermissionDenied, "permission denied").Wrap(cause)
+}
@@ -0,0 +1,64 @@
+// Package authzgin enforces the authz policies on the requests of the httpgin module.
+package authzgin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/things-kit/module/auth"
+	"github.com/things-kit/module/authz"
+	"github.com/things-kit/module/errmap/errmapgin"
+	"github.com/things-kit/module/httpgin"
+	"go.uber.org/fx"
+)
+
+// Module installs the Authorization middleware at httpgin.OrderAuthorization.
+// It requires authz.Module; with auth.Module present, auth.public_routes are
+// not checked.
+var Module = fx.Module("authzgin",
+	httpgin.AsGinMiddleware(func(p MiddlewareParams) gin.HandlerFunc {
+		return Authorization(p.Engine, p.public())
+	}, httpgin.OrderAuthorization),
+)
+
+// MiddlewareParams contains the dependencies of the middleware of Module.
+type MiddlewareParams struct {
+	fx.In
+	Engine authz.Engine
+
+	// Auth provides auth.public_routes when auth.Module is present
+	Auth *auth.Verifier `optional:"true"`
+}
+
+func (p MiddlewareParams) public() auth.Allowlist {
+	if p.Auth == nil {
+		return nil
+	}
+	return p.Auth.Config().PublicRoutes
+}
+
+// Authorization checks every request against engine with the auth.Principal
+// of its context. The resource is the route pattern, e.g. "/orders/:id", with
+// the HTTP method. Denied requests get a 403 (401 for anonymous callers) and
+// engine failures a 500, written by errmapgin.WriteError. Routes matching
+// public and requests matching no route are not checked.
+func Authorization(engine authz.Engine, public auth.Allowlist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" || public.Match(route, c.Request.Method+" "+route) {
+			c.Next()
+			return
+		}
+
+		principal, _ := auth.PrincipalFrom(c.Request.Context())
+		err := authz.Check(c.Request.Context(), engine, authz.Request{
+			Principal: principal,
+			Resource:  route,
+			Method:    c.Request.Method,
+			Input:     c.Request,
+		})
+		if err != nil {
+			errmapgin.WriteError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
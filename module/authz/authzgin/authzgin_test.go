@@ -0,0 +1,126 @@
+package authzgin_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/things-kit/module/auth"
+	"github.com/things-kit/module/auth/authgin"
+	"github.com/things-kit/module/auth/authtest"
+	"github.com/things-kit/module/authz"
+	"github.com/things-kit/module/authz/authzgin"
+	"github.com/things-kit/module/httpgin"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+type meHandler struct{}
+
+func (meHandler) RegisterRoutes(engine *gin.Engine) {
+	engine.GET("/healthz", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	engine.DELETE("/me", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+}
+
+// TestAuthorization verifies that requests are checked against the principal authenticated by authgin
+func TestAuthorization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	issuer := authtest.NewIssuer(t)
+	v := viper.New()
+	v.Set("http.port", 0)
+	v.Set("auth.public_routes", []string{"/healthz"})
+	v.Set("authz.rules", []map[string]any{
+		{"resources": []string{"DELETE /me"}, "roles": []string{"admin"}},
+	})
+	issuer.Configure(v)
+
+	var server *httpgin.GinServer
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		httpgin.Module,
+		auth.Module,
+		authgin.Module,
+		authz.Module,
+		authzgin.Module,
+		httpgin.AsGinHandler(func() meHandler { return meHandler{} }),
+		fx.Populate(&server),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		code          int
+	}{
+		{name: "role granted", method: http.MethodDelete, path: "/me", authorization: "Bearer " + issuer.Token(t, "user-1", authtest.WithRoles("admin")), code: http.StatusNoContent},
+		{name: "role missing", method: http.MethodDelete, path: "/me", authorization: "Bearer " + issuer.Token(t, "user-1"), code: http.StatusForbidden},
+		{name: "public route", method: http.MethodGet, path: "/healthz", code: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			server.Engine().ServeHTTP(w, req)
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
+
+// TestAuthorizationErrors verifies the responses to anonymous callers and engine failures
+func TestAuthorizationErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := authz.EngineFunc(func(_ context.Context, req authz.Request) (authz.Decision, error) {
+		if req.Method == http.MethodDelete {
+			return authz.Decision{}, errors.New("policy store unavailable")
+		}
+		return authz.Decision{Reason: "no rule"}, nil
+	})
+
+	router := gin.New()
+	router.Use(authzgin.Authorization(engine, nil))
+	router.Any("/orders/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	tests := []struct {
+		name   string
+		method string
+		code   int
+		body   string
+	}{
+		{name: "anonymous", method: http.MethodGet, code: http.StatusUnauthorized, body: `{"code": 16, "message": "authentication required"}`},
+		{name: "engine failure", method: http.MethodDelete, code: http.StatusInternalServerError, body: `{"code": 13, "message": "authorization failed"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, "/orders/7", nil))
+			assert.Equal(t, tt.code, w.Code)
+			assert.JSONEq(t, tt.body, w.Body.String())
+		})
+	}
+}
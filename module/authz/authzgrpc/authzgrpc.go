@@ -0,0 +1,73 @@
+// Package authzgrpc enforces the authz policies on the calls of the grpc module.
+package authzgrpc
+
+import (
+	"context"
+
+	"github.com/things-kit/module/auth"
+	"github.com/things-kit/module/authz"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+)
+
+// Module chains the authorization interceptors at
+// grpcmodule.OrderAuthorization. It requires authz.Module; with auth.Module
+// present, auth.public_methods are not checked.
+var Module = fx.Module("authzgrpc",
+	grpcmodule.AsUnaryInterceptor(func(p InterceptorParams) grpc.UnaryServerInterceptor {
+		return UnaryServerInterceptor(p.Engine, p.public())
+	}, grpcmodule.OrderAuthorization),
+	grpcmodule.AsStreamInterceptor(func(p InterceptorParams) grpc.StreamServerInterceptor {
+		return StreamServerInterceptor(p.Engine, p.public())
+	}, grpcmodule.OrderAuthorization),
+)
+
+// InterceptorParams contains the dependencies of the interceptors of Module.
+type InterceptorParams struct {
+	fx.In
+	Engine authz.Engine
+
+	// Auth provides auth.public_methods when auth.Module is present
+	Auth *auth.Verifier `optional:"true"`
+}
+
+func (p InterceptorParams) public() auth.Allowlist {
+	if p.Auth == nil {
+		return nil
+	}
+	return p.Auth.Config().PublicMethods
+}
+
+// UnaryServerInterceptor checks every call against engine with the
+// auth.Principal of its context, failing denied calls with
+// codes.PermissionDenied (codes.Unauthenticated for anonymous callers).
+// Methods matching public are not checked.
+func UnaryServerInterceptor(engine authz.Engine, public auth.Allowlist) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !public.Match(info.FullMethod) {
+			if err := authorize(ctx, engine, info.FullMethod, req); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the stream counterpart of UnaryServerInterceptor.
+// Streams are checked once, when they are opened.
+func StreamServerInterceptor(engine authz.Engine, public auth.Allowlist) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !public.Match(info.FullMethod) {
+			if err := authorize(ss.Context(), engine, info.FullMethod, nil); err != nil {
+				return err
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+func authorize(ctx context.Context, engine authz.Engine, method string, req any) error {
+	principal, _ := auth.PrincipalFrom(ctx)
+	return authz.Check(ctx, engine, authz.Request{Principal: principal, Resource: method, Input: req})
+}
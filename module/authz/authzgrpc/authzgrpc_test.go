@@ -0,0 +1,46 @@
+package authzgrpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/auth"
+	"github.com/things-kit/module/authz"
+	"github.com/things-kit/module/authz/authzgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestUnaryServerInterceptor verifies that calls are checked against the principal in context
+func TestUnaryServerInterceptor(t *testing.T) {
+	engine, err := authz.NewRuleEngine(authz.RuleEngineParams{
+		Config: &authz.Config{Default: authz.DefaultDeny},
+		Rules: []authz.Rule{
+			{Resources: auth.Allowlist{"/orders.v1.Orders/DeleteOrder"}, Roles: []string{"admin"}},
+		},
+	})
+	require.NoError(t, err)
+
+	interceptor := authzgrpc.UnaryServerInterceptor(engine, auth.Allowlist{"/grpc.health.v1.Health/*"})
+	call := func(method string, principal *auth.Principal) error {
+		ctx := context.Background()
+		if principal != nil {
+			ctx = auth.WithPrincipal(ctx, principal)
+		}
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+			func(context.Context, any) (any, error) { return nil, nil })
+		return err
+	}
+
+	admin := &auth.Principal{Subject: "ada", Roles: []string{"admin"}}
+	viewer := &auth.Principal{Subject: "bob", Roles: []string{"viewer"}}
+
+	assert.NoError(t, call("/orders.v1.Orders/DeleteOrder", admin))
+	assert.Equal(t, codes.PermissionDenied, status.Code(call("/orders.v1.Orders/DeleteOrder", viewer)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(call("/orders.v1.Orders/DeleteOrder", nil)))
+	assert.Equal(t, codes.PermissionDenied, status.Code(call("/orders.v1.Orders/ListOrders", admin)))
+	assert.NoError(t, call("/grpc.health.v1.Health/Check", nil))
+}
@@ -0,0 +1,124 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.uber.org/fx"
+)
+
+// RuleEngineParams contains the dependencies of NewRuleEngine.
+type RuleEngineParams struct {
+	fx.In
+	Config *Config
+	Rules  []Rule `group:"authz.rules"`
+}
+
+// RuleEngine authorizes calls with the first rule matching their resource,
+// trying the rules of authz.rules, then authz.rules_file, then those
+// registered with AsRule. Resources matching no rule get authz.default.
+type RuleEngine struct {
+	rules []Rule
+	def   string
+}
+
+// NewRuleEngine creates a RuleEngine, reading authz.rules_file when set.
+func NewRuleEngine(p RuleEngineParams) (*RuleEngine, error) {
+	switch p.Config.Default {
+	case DefaultAllow, DefaultAuthenticated, DefaultDeny:
+	default:
+		return nil, fmt.Errorf("authz: invalid default %q: must be allow, authenticated or deny", p.Config.Default)
+	}
+
+	rules := slices.Clone(p.Config.Rules)
+	if p.Config.RulesFile != "" {
+		fileRules, err := LoadRules(p.Config.RulesFile)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+	rules = append(rules, p.Rules...)
+
+	for i, r := range rules {
+		if len(r.Resources) == 0 {
+			return nil, fmt.Errorf("authz: rule %d has no resources", i)
+		}
+	}
+
+	return &RuleEngine{rules: rules, def: p.Config.Default}, nil
+}
+
+// LoadRules reads the "rules" list of a YAML, JSON or TOML file.
+//
+// Example file:
+//
+//	rules:
+//	  - resources: ["/orders.v1.Orders/DeleteOrder", "DELETE /orders/:id"]
+//	    roles: [admin]
+//	  - resources: ["/orders.v1.Orders/*", "GET /orders/*"]
+//	    scopes: ["orders:read"]
+func LoadRules(path string) ([]Rule, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("authz: read rules file: %w", err)
+	}
+
+	var rules []Rule
+	if err := v.UnmarshalKey("rules", &rules); err != nil {
+		return nil, fmt.Errorf("authz: parse rules file: %w", err)
+	}
+	return rules, nil
+}
+
+// Authorize implements Engine.
+func (e *RuleEngine) Authorize(_ context.Context, req Request) (Decision, error) {
+	names := []string{req.Resource}
+	if req.Method != "" {
+		names = append(names, req.Method+" "+req.Resource)
+	}
+
+	for _, r := range e.rules {
+		if r.Resources.Match(names...) {
+			return r.evaluate(req), nil
+		}
+	}
+
+	switch e.def {
+	case DefaultAllow:
+		return Decision{Allowed: true}, nil
+	case DefaultAuthenticated:
+		if req.Principal == nil {
+			return Decision{Reason: "no rule matches and the caller is anonymous"}, nil
+		}
+		return Decision{Allowed: true}, nil
+	}
+	return Decision{Reason: "no rule matches"}, nil
+}
+
+// evaluate applies the rule to a matching request.
+func (r Rule) evaluate(req Request) Decision {
+	p := req.Principal
+	switch {
+	case r.Deny:
+		return Decision{Reason: "denied by rule"}
+	case r.Public:
+		return Decision{Allowed: true}
+	case p == nil:
+		return Decision{Reason: "authentication required"}
+	}
+
+	if len(r.Roles) > 0 && !slices.ContainsFunc(r.Roles, p.HasRole) {
+		return Decision{Reason: "requires one of roles " + strings.Join(r.Roles, ", ")}
+	}
+	for _, scope := range r.Scopes {
+		if !p.HasScope(scope) {
+			return Decision{Reason: "requires scope " + scope}
+		}
+	}
+	return Decision{Allowed: true}
+}
@@ -0,0 +1,96 @@
+package authz_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/auth"
+	"github.com/things-kit/module/authz"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestRuleEngine verifies rule matching, role and scope requirements and default policies
+func TestRuleEngine(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesFile, []byte(`
+rules:
+  - resources: ["/orders.v1.Orders/*", "GET /orders/*"]
+    scopes: ["orders:read"]
+`), 0o600))
+
+	newEngine := func(def string) authz.Engine {
+		engine, err := authz.NewRuleEngine(authz.RuleEngineParams{
+			Config: &authz.Config{
+				Default:   def,
+				RulesFile: rulesFile,
+				Rules: []authz.Rule{
+					{Resources: auth.Allowlist{"/grpc.health.v1.Health/*"}, Public: true},
+					{Resources: auth.Allowlist{"/orders.v1.Orders/Purge"}, Deny: true},
+					{Resources: auth.Allowlist{"/orders.v1.Orders/DeleteOrder"}, Roles: []string{"admin", "owner"}},
+				},
+			},
+			Rules: []authz.Rule{
+				{Resources: auth.Allowlist{"/orders.v1.Orders/Purge"}, Public: true},
+				{Resources: auth.Allowlist{"DELETE /orders/:id"}, Roles: []string{"admin", "owner"}},
+			},
+		})
+		require.NoError(t, err)
+		return engine
+	}
+
+	admin := &auth.Principal{Subject: "ada", Roles: []string{"admin"}}
+	reader := &auth.Principal{Subject: "bob", Scopes: []string{"orders:read"}}
+
+	tests := []struct {
+		name      string
+		def       string
+		principal *auth.Principal
+		resource  string
+		method    string
+		code      codes.Code
+	}{
+		{name: "config rule before registered rule", principal: admin, resource: "/orders.v1.Orders/Purge", code: codes.PermissionDenied},
+		{name: "role granted", principal: admin, resource: "/orders.v1.Orders/DeleteOrder", code: codes.OK},
+		{name: "role missing", principal: reader, resource: "/orders.v1.Orders/DeleteOrder", code: codes.PermissionDenied},
+		{name: "registered HTTP rule with method", principal: reader, resource: "/orders/:id", method: "DELETE", code: codes.PermissionDenied},
+		{name: "scope from rules file", principal: reader, resource: "/orders.v1.Orders/ListOrders", code: codes.OK},
+		{name: "scope missing", principal: admin, resource: "/orders/:id", method: "GET", code: codes.PermissionDenied},
+		{name: "anonymous", resource: "/orders.v1.Orders/ListOrders", code: codes.Unauthenticated},
+		{name: "public", resource: "/grpc.health.v1.Health/Check", code: codes.OK},
+		{name: "default authenticated", def: authz.DefaultAuthenticated, principal: reader, resource: "/users.v1.Users/Get", code: codes.OK},
+		{name: "default authenticated anonymous", def: authz.DefaultAuthenticated, resource: "/users.v1.Users/Get", code: codes.Unauthenticated},
+		{name: "default deny", def: authz.DefaultDeny, principal: admin, resource: "/users.v1.Users/Get", code: codes.PermissionDenied},
+		{name: "default allow", def: authz.DefaultAllow, resource: "/users.v1.Users/Get", code: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := tt.def
+			if def == "" {
+				def = authz.DefaultDeny
+			}
+			err := authz.Check(context.Background(), newEngine(def), authz.Request{
+				Principal: tt.principal,
+				Resource:  tt.resource,
+				Method:    tt.method,
+			})
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+// TestNewRuleEngineErrors verifies that invalid configuration fails
+func TestNewRuleEngineErrors(t *testing.T) {
+	_, err := authz.NewRuleEngine(authz.RuleEngineParams{Config: &authz.Config{Default: "maybe"}})
+	assert.ErrorContains(t, err, "invalid default")
+
+	_, err = authz.NewRuleEngine(authz.RuleEngineParams{Config: &authz.Config{
+		Default: authz.DefaultDeny,
+		Rules:   []authz.Rule{{Roles: []string{"admin"}}},
+	}})
+	assert.ErrorContains(t, err, "no resources")
+}
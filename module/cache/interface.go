@@ -8,14 +8,26 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+var (
+	// ErrNotFound is returned (possibly wrapped) by Get and GetBytes when the key
+	// does not exist. Use errors.Is to check for it regardless of the backend.
+	ErrNotFound = errors.New("cache: key not found")
+
+	// ErrNotSupported is returned by decorators when the wrapped cache does not
+	// implement an optional interface such as TaggedCache or PatternCache.
+	ErrNotSupported = errors.New("cache: operation not supported")
+)
+
 // Cache represents a distributed cache that can store and retrieve key-value pairs.
 // Implementations should handle serialization, expiration, and error handling.
 type Cache interface {
 	// Get retrieves the value for the given key.
-	// Returns an error if the key doesn't exist or if there's a connection issue.
+	// Returns an error matching ErrNotFound if the key doesn't exist,
+	// or another error if there's a connection issue.
 	Get(ctx context.Context, key string) (string, error)
 
 	// Set stores a value with the given key and expiration duration.
@@ -70,3 +82,31 @@ type BatchCache interface {
 	// MDelete removes multiple keys at once.
 	MDelete(ctx context.Context, keys ...string) error
 }
+
+// TaggedCache extends Cache with tag-based invalidation.
+// Implementations can optionally implement this interface to let callers
+// invalidate groups of related entries (e.g., everything about one user) at once.
+type TaggedCache interface {
+	Cache
+
+	// SetWithTags stores a value like Set and associates the key with the given tags.
+	SetWithTags(ctx context.Context, key string, value string, expiration time.Duration, tags ...string) error
+
+	// SetBytesWithTags stores a raw byte value like SetBytes and associates the key with the given tags.
+	SetBytesWithTags(ctx context.Context, key string, value []byte, expiration time.Duration, tags ...string) error
+
+	// InvalidateTag deletes every key associated with any of the given tags.
+	InvalidateTag(ctx context.Context, tags ...string) error
+}
+
+// PatternCache extends Cache with pattern-based deletion.
+// Implementations can optionally implement this interface.
+type PatternCache interface {
+	Cache
+
+	// DeletePattern deletes all keys matching a glob-style pattern
+	// (*, ? and [...] as in Redis MATCH) and returns the number of keys deleted.
+	// Implementations must iterate incrementally and never block the backend
+	// (e.g., Redis implementations use SCAN, never KEYS).
+	DeletePattern(ctx context.Context, pattern string) (int64, error)
+}
@@ -0,0 +1,73 @@
+// Package errmapgin applies the errmap Mapper to the requests of the httpgin module.
+package errmapgin
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/things-kit/module/errmap"
+	"github.com/things-kit/module/httpgin"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"google.golang.org/grpc/codes"
+)
+
+// Module installs the ErrorMapping middleware at httpgin.OrderErrors.
+// It requires errmap.Module.
+var Module = fx.Module("errmapgin",
+	httpgin.AsGinMiddleware(ErrorMapping, httpgin.OrderErrors),
+)
+
+// ErrorMapping writes the errors handlers attach with c.Error as mapped
+// responses, in the google.rpc.Status format of httpgin.WriteStatus.
+// Internal errors are logged and replaced by a generic message. Responses
+// already written by the handler are left untouched.
+//
+// Example:
+//
+//	func (h *UserHandler) get(c *gin.Context) {
+//	    user, err := h.store.Get(c.Request.Context(), c.Param("id"))
+//	    if err != nil {
+//	        _ = c.Error(err) // e.g. store.ErrNotFound mapped to 404
+//	        return
+//	    }
+//	    c.JSON(http.StatusOK, user)
+//	}
+func ErrorMapping(mapper *errmap.Mapper) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		WriteResult(c, mapper.Resolve(c.Request.Context(), err,
+			log.Field{Key: "method", Value: c.Request.Method},
+			log.Field{Key: "path", Value: c.FullPath()},
+		))
+	}
+}
+
+// WriteError writes err as a google.rpc.Status JSON body and aborts the chain.
+// An *errmap.Error keeps its code and message; any other error is attached
+// with c.Error and answered with a generic Internal status.
+func WriteError(c *gin.Context, err error) {
+	var e *errmap.Error
+	if !errors.As(err, &e) || e == nil {
+		_ = c.Error(err)
+		e = errmap.New(codes.Internal, "internal error")
+	}
+	WriteResult(c, errmap.Result{Status: e.GRPCStatus(), HTTPStatus: errmap.HTTPStatusFromCode(e.Code)})
+}
+
+// WriteResult writes result as a google.rpc.Status JSON body, with a
+// Retry-After header when it has a retry delay, and aborts the chain.
+func WriteResult(c *gin.Context, result errmap.Result) {
+	if result.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+	}
+	httpgin.WriteStatus(c, result.HTTPStatus, result.Status)
+}
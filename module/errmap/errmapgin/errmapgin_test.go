@@ -0,0 +1,122 @@
+package errmapgin_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/errmap"
+	"github.com/things-kit/module/errmap/errmapgin"
+	"github.com/things-kit/module/httpgin"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc/codes"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+var errUserNotFound = errors.New("user not found")
+
+// TestErrorMapping verifies mapped statuses, google.rpc.Status bodies and untouched written responses
+func TestErrorMapping(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mapper := errmap.NewMapper(errmap.MapperParams{
+		Config: &errmap.Config{InternalMessage: "internal error"},
+		Logger: nopLogger{},
+		Rules:  []errmap.Rule{errmap.Sentinel(errUserNotFound, codes.NotFound, errmap.WithReason("USER_NOT_FOUND"))},
+	})
+
+	engine := gin.New()
+	engine.Use(errmapgin.ErrorMapping(mapper))
+	engine.GET("/users/:id", func(c *gin.Context) {
+		_ = c.Error(fmt.Errorf("load user %s: %w", c.Param("id"), errUserNotFound))
+	})
+	engine.GET("/busy", func(c *gin.Context) {
+		_ = c.Error(errmap.Unavailable("try again later", 1500*time.Millisecond))
+	})
+	engine.GET("/written", func(c *gin.Context) {
+		c.String(http.StatusTeapot, "teapot")
+		_ = c.Error(errors.New("logged only"))
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var body struct {
+		Code    int              `json:"code"`
+		Message string           `json:"message"`
+		Details []map[string]any `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, int(codes.NotFound), body.Code)
+	assert.Equal(t, "user not found", body.Message)
+	require.Len(t, body.Details, 1)
+	assert.Equal(t, "type.googleapis.com/google.rpc.ErrorInfo", body.Details[0]["@type"])
+	assert.Equal(t, "USER_NOT_FOUND", body.Details[0]["reason"])
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/busy", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/written", nil))
+	assert.Equal(t, http.StatusTeapot, w.Code)
+	assert.Equal(t, "teapot", w.Body.String())
+}
+
+type usersHandler struct{}
+
+func (usersHandler) RegisterRoutes(engine *gin.Engine) {
+	engine.GET("/users/:id", func(c *gin.Context) {
+		_ = c.Error(fmt.Errorf("load user %s: %w", c.Param("id"), errUserNotFound))
+	})
+}
+
+// TestModule verifies that the middleware is installed on the httpgin engine
+func TestModule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	v := viper.New()
+	v.Set("http.port", 0)
+
+	var server *httpgin.GinServer
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		httpgin.Module,
+		errmap.Module,
+		errmapgin.Module,
+		errmap.AsRule(errmap.Sentinel(errUserNotFound, codes.NotFound)),
+		httpgin.AsGinHandler(func() usersHandler { return usersHandler{} }),
+		fx.Populate(&server),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	w := httptest.NewRecorder()
+	server.Engine().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"code": 5, "message": "user not found"}`, w.Body.String())
+}
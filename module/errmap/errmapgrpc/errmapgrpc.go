@@ -0,0 +1,42 @@
+// Package errmapgrpc applies the errmap Mapper to the calls of the grpc module.
+package errmapgrpc
+
+import (
+	"context"
+
+	"github.com/things-kit/module/errmap"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+)
+
+// Module chains the error mapping interceptors at grpcmodule.OrderErrors.
+// It requires errmap.Module.
+var Module = fx.Module("errmapgrpc",
+	grpcmodule.AsUnaryInterceptor(UnaryServerInterceptor, grpcmodule.OrderErrors),
+	grpcmodule.AsStreamInterceptor(StreamServerInterceptor, grpcmodule.OrderErrors),
+)
+
+// UnaryServerInterceptor converts handler errors into gRPC statuses through
+// mapper: mapped domain errors get their code and details, and internal
+// errors are logged and replaced by a generic message.
+func UnaryServerInterceptor(mapper *errmap.Mapper) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, mapper.Resolve(ctx, err, log.Field{Key: "method", Value: info.FullMethod}).Status.Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor is the stream counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor(mapper *errmap.Mapper) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return mapper.Resolve(ss.Context(), err, log.Field{Key: "method", Value: info.FullMethod}).Status.Err()
+		}
+		return nil
+	}
+}
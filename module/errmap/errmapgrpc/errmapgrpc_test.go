@@ -0,0 +1,116 @@
+package errmapgrpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/errmap"
+	"github.com/things-kit/module/errmap/errmapgrpc"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+var errOrderNotFound = errors.New("order not found")
+
+// TestUnaryServerInterceptor verifies that handler errors become mapped statuses
+func TestUnaryServerInterceptor(t *testing.T) {
+	var mapper *errmap.Mapper
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(viper.New()),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		errmap.Module,
+		errmap.AsRule(errmap.Sentinel(errOrderNotFound, codes.NotFound, errmap.WithReason("ORDER_NOT_FOUND"))),
+		fx.Populate(&mapper),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	interceptor := errmapgrpc.UnaryServerInterceptor(mapper)
+	call := func(err error) *status.Status {
+		_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Get"},
+			func(context.Context, any) (any, error) { return nil, err })
+		return status.Convert(err)
+	}
+
+	st := call(fmt.Errorf("select order 7: %w", errOrderNotFound))
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "order not found", st.Message())
+	require.Len(t, st.Details(), 1)
+	assert.Equal(t, "ORDER_NOT_FOUND", st.Details()[0].(*errdetails.ErrorInfo).Reason)
+
+	st = call(errors.New("dial tcp 10.0.0.3:5432: connection refused"))
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Equal(t, "internal error", st.Message())
+}
+
+// failingHealth is a health service failing with a domain error
+type failingHealth struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (failingHealth) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	return nil, fmt.Errorf("check: %w", errOrderNotFound)
+}
+
+// TestModule verifies that the interceptors are chained by the grpc module
+func TestModule(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	v := viper.New()
+	v.Set("grpc.port", port)
+	v.Set("grpc.health.enabled", false)
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		grpcmodule.Module,
+		errmap.Module,
+		errmapgrpc.Module,
+		errmap.AsRule(errmap.Sentinel(errOrderNotFound, codes.NotFound)),
+		grpcmodule.AsGrpcService(func(struct{ fx.In }) healthpb.HealthServer { return failingHealth{} }, healthpb.RegisterHealthServer),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	conn, err := grpc.Dial("127.0.0.1:"+strconv.Itoa(port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "order not found", status.Convert(err).Message())
+}
@@ -0,0 +1,117 @@
+// Package errmap maps domain errors to gRPC status codes and HTTP statuses.
+// Handlers return plain Go errors; the interceptors of errmapgrpc and the
+// middleware of errmapgin resolve them through a Mapper into a google.rpc.Status with
+// structured details, and internal errors are logged instead of leaked.
+package errmap
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// FieldViolation describes one invalid field of a request.
+// It is sent to clients as a google.rpc.BadRequest detail.
+type FieldViolation struct {
+	Field       string // Path of the field, e.g. "user.email"
+	Description string // Why the value is invalid
+}
+
+// Error is an error carrying everything needed to build a client-facing status.
+// Handlers can return it directly or wrap it; the first Error in the chain wins.
+type Error struct {
+	Code       codes.Code
+	Message    string            // Client-facing message
+	Reason     string            // Machine-readable reason (UPPER_SNAKE_CASE), sent as google.rpc.ErrorInfo
+	Metadata   map[string]string // Sent with the reason in google.rpc.ErrorInfo
+	Violations []FieldViolation  // Sent as google.rpc.BadRequest
+	RetryAfter time.Duration     // Sent as google.rpc.RetryInfo (and Retry-After over HTTP)
+	Err        error             // Underlying cause, logged but never sent to clients
+}
+
+// New creates an Error with a code and a client-facing message.
+func New(code codes.Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Newf creates an Error with a formatted client-facing message.
+func Newf(code codes.Code, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// InvalidArgument creates a codes.InvalidArgument Error listing the invalid fields.
+func InvalidArgument(message string, violations ...FieldViolation) *Error {
+	return &Error{Code: codes.InvalidArgument, Message: message, Violations: violations}
+}
+
+// Unavailable creates a codes.Unavailable Error telling clients when to retry.
+func Unavailable(message string, retryAfter time.Duration) *Error {
+	return &Error{Code: codes.Unavailable, Message: message, RetryAfter: retryAfter}
+}
+
+// WithReason sets the machine-readable reason and its metadata.
+func (e *Error) WithReason(reason string, metadata map[string]string) *Error {
+	e.Reason = reason
+	e.Metadata = metadata
+	return e
+}
+
+// Wrap sets the underlying cause.
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying cause, or nil for a nil *Error.
+func (e *Error) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// GRPCStatus returns the status of e with its details, so that gRPC servers
+// send it correctly even without a Mapper. The ErrorInfo domain is only set
+// when the error is resolved by a Mapper.
+func (e *Error) GRPCStatus() *status.Status {
+	return e.status("")
+}
+
+// status builds the status of e, attaching its details.
+func (e *Error) status(domain string) *status.Status {
+	var details []protoiface.MessageV1
+	if e.Reason != "" {
+		details = append(details, &errdetails.ErrorInfo{Reason: e.Reason, Domain: domain, Metadata: e.Metadata})
+	}
+	if len(e.Violations) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, v := range e.Violations {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: v.Field, Description: v.Description})
+		}
+		details = append(details, br)
+	}
+	if e.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter)})
+	}
+
+	st := status.New(e.Code, e.Message)
+	if len(details) > 0 {
+		if withDetails, err := st.WithDetails(details...); err == nil {
+			st = withDetails
+		}
+	}
+	return st
+}
@@ -0,0 +1,250 @@
+package errmap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Module provides the error mapper to the application. errmapgrpc.Module and
+// errmapgin.Module apply it to every call of the grpc and httpgin modules.
+var Module = fx.Module("errmap",
+	fx.Provide(
+		NewConfig,
+		NewMapper,
+	),
+)
+
+// Config holds the error mapping configuration.
+type Config struct {
+	Domain          string `mapstructure:"domain"`           // google.rpc.ErrorInfo domain, e.g. "users.example.com"
+	InternalMessage string `mapstructure:"internal_message"` // Message sent in place of internal errors
+}
+
+// NewConfig creates the error mapping configuration from Viper.
+func NewConfig(v *viper.Viper) *Config {
+	cfg := &Config{
+		InternalMessage: "internal error",
+	}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("errors", cfg)
+	}
+
+	return cfg
+}
+
+// Rule maps matching errors to a code. Create rules with Sentinel or Type.
+type Rule struct {
+	match      func(error) (error, bool)
+	code       codes.Code
+	httpStatus int
+	reason     string
+}
+
+// RuleOption customizes a Rule.
+type RuleOption func(*Rule)
+
+// WithHTTPStatus overrides the HTTP status derived from the rule's code.
+func WithHTTPStatus(httpStatus int) RuleOption {
+	return func(r *Rule) { r.httpStatus = httpStatus }
+}
+
+// WithReason attaches a google.rpc.ErrorInfo with this reason to matching errors.
+func WithReason(reason string) RuleOption {
+	return func(r *Rule) { r.reason = reason }
+}
+
+// Sentinel maps errors matching target with errors.Is. The client-facing
+// message is target's message, never the messages of the errors wrapping it.
+//
+// Example:
+//
+//	errmap.Sentinel(store.ErrNotFound, codes.NotFound, errmap.WithReason("USER_NOT_FOUND"))
+func Sentinel(target error, code codes.Code, opts ...RuleOption) Rule {
+	r := Rule{
+		code: code,
+		match: func(err error) (error, bool) {
+			return target, errors.Is(err, target)
+		},
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	return r
+}
+
+// Type maps errors of type T found with errors.As. The client-facing message
+// is the matched error's message.
+//
+// Example:
+//
+//	errmap.Type[*store.ConflictError](codes.AlreadyExists, errmap.WithHTTPStatus(http.StatusConflict))
+func Type[T error](code codes.Code, opts ...RuleOption) Rule {
+	r := Rule{
+		code: code,
+		match: func(err error) (error, bool) {
+			var target T
+			if errors.As(err, &target) {
+				return target, true
+			}
+			return nil, false
+		},
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	return r
+}
+
+// AsRule registers a rule with the application's Mapper.
+//
+// Example:
+//
+//	errmap.AsRule(errmap.Sentinel(store.ErrNotFound, codes.NotFound))
+func AsRule(rule Rule) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			func() Rule { return rule },
+			fx.ResultTags(`group:"errmap.rules"`),
+		),
+	)
+}
+
+// Result is the client-facing form of an error.
+type Result struct {
+	Status     *status.Status // gRPC status with details
+	HTTPStatus int
+	RetryAfter time.Duration // Non-zero when clients are told when to retry
+}
+
+// MapperParams contains the dependencies of NewMapper.
+type MapperParams struct {
+	fx.In
+	Config *Config
+	Logger log.Logger
+	Rules  []Rule `group:"errmap.rules"`
+}
+
+// Mapper resolves errors into client-facing statuses.
+type Mapper struct {
+	cfg    *Config
+	logger log.Logger
+
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+// NewMapper creates a Mapper with the rules contributed with AsRule.
+func NewMapper(p MapperParams) *Mapper {
+	return &Mapper{cfg: p.Config, logger: p.Logger, rules: p.Rules}
+}
+
+// Register adds rules. Rules are tried in registration order.
+func (m *Mapper) Register(rules ...Rule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = append(m.rules, rules...)
+}
+
+// Resolve converts err into its client-facing form, in this order:
+//   - an *Error in the chain is used as is
+//   - gRPC status errors keep their status
+//   - context cancellation and deadlines map to Canceled and DeadlineExceeded
+//   - the first matching rule
+//   - anything else is an internal error
+//
+// Internal errors (Unknown, Internal, DataLoss and unmapped errors) are logged
+// with fields; unmapped errors are sent as Internal with the configured message.
+func (m *Mapper) Resolve(ctx context.Context, err error, fields ...log.Field) Result {
+	if err == nil {
+		return Result{Status: status.New(codes.OK, ""), HTTPStatus: http.StatusOK}
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		m.logInternal(ctx, e.Code, err, fields)
+		return m.result(e, 0)
+	}
+
+	if st, ok := status.FromError(err); ok {
+		m.logInternal(ctx, st.Code(), err, fields)
+		return Result{Status: st, HTTPStatus: HTTPStatusFromCode(st.Code())}
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return m.result(New(codes.Canceled, "request canceled"), 0)
+	case errors.Is(err, context.DeadlineExceeded):
+		return m.result(New(codes.DeadlineExceeded, "deadline exceeded"), 0)
+	}
+
+	m.mu.RLock()
+	rules := m.rules
+	m.mu.RUnlock()
+	for _, r := range rules {
+		if matched, ok := r.match(err); ok {
+			m.logInternal(ctx, r.code, err, fields)
+			return m.result(&Error{Code: r.code, Message: matched.Error(), Reason: r.reason}, r.httpStatus)
+		}
+	}
+
+	m.logInternal(ctx, codes.Internal, err, fields)
+	return m.result(New(codes.Internal, m.cfg.InternalMessage), 0)
+}
+
+// result builds the client-facing form of e.
+func (m *Mapper) result(e *Error, httpStatus int) Result {
+	if httpStatus == 0 {
+		httpStatus = HTTPStatusFromCode(e.Code)
+	}
+	return Result{Status: e.status(m.cfg.Domain), HTTPStatus: httpStatus, RetryAfter: e.RetryAfter}
+}
+
+// logInternal logs errors whose code indicates a server-side failure.
+func (m *Mapper) logInternal(ctx context.Context, code codes.Code, err error, fields []log.Field) {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss:
+		m.logger.ErrorC(ctx, "Internal error", err, fields...)
+	}
+}
+
+// HTTPStatusFromCode returns the HTTP status conventionally used for a gRPC code.
+// See https://github.com/googleapis/googleapis/blob/master/google/rpc/code.proto
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // Client Closed Request
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,147 @@
+package errmap_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/errmap"
+	"github.com/things-kit/module/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordingLogger records logged errors
+type recordingLogger struct{ errors []error }
+
+func (l *recordingLogger) Info(string, ...log.Field) {}
+func (l *recordingLogger) Error(_ string, err error, _ ...log.Field) {
+	l.errors = append(l.errors, err)
+}
+func (l *recordingLogger) Debug(string, ...log.Field)                  {}
+func (l *recordingLogger) Warn(string, ...log.Field)                   {}
+func (l *recordingLogger) InfoC(context.Context, string, ...log.Field) {}
+func (l *recordingLogger) ErrorC(_ context.Context, _ string, err error, _ ...log.Field) {
+	l.errors = append(l.errors, err)
+}
+func (l *recordingLogger) DebugC(context.Context, string, ...log.Field)       {}
+func (l *recordingLogger) WarnC(context.Context, string, error, ...log.Field) {}
+
+var errUserNotFound = errors.New("user not found")
+
+type conflictError struct{ id string }
+
+func (e *conflictError) Error() string { return "user " + e.id + " already exists" }
+
+func newMapper(logger log.Logger) *errmap.Mapper {
+	return errmap.NewMapper(errmap.MapperParams{
+		Config: &errmap.Config{Domain: "users.example.com", InternalMessage: "internal error"},
+		Logger: logger,
+		Rules: []errmap.Rule{
+			errmap.Sentinel(errUserNotFound, codes.NotFound, errmap.WithReason("USER_NOT_FOUND")),
+			errmap.Type[*conflictError](codes.AlreadyExists, errmap.WithHTTPStatus(http.StatusUnprocessableEntity)),
+		},
+	})
+}
+
+// TestResolve verifies codes, messages and HTTP statuses
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		code       codes.Code
+		message    string
+		httpStatus int
+		logged     bool
+	}{
+		{
+			name:       "wrapped sentinel hides wrapping messages",
+			err:        fmt.Errorf("query failed for id 42: %w", errUserNotFound),
+			code:       codes.NotFound,
+			message:    "user not found",
+			httpStatus: http.StatusNotFound,
+		},
+		{
+			name:       "type with HTTP override",
+			err:        fmt.Errorf("insert: %w", &conflictError{id: "ada"}),
+			code:       codes.AlreadyExists,
+			message:    "user ada already exists",
+			httpStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rich error",
+			err:        errmap.New(codes.FailedPrecondition, "account is locked"),
+			code:       codes.FailedPrecondition,
+			message:    "account is locked",
+			httpStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "status passes through",
+			err:        status.Error(codes.PermissionDenied, "nope"),
+			code:       codes.PermissionDenied,
+			message:    "nope",
+			httpStatus: http.StatusForbidden,
+		},
+		{
+			name:       "deadline",
+			err:        fmt.Errorf("call: %w", context.DeadlineExceeded),
+			code:       codes.DeadlineExceeded,
+			message:    "deadline exceeded",
+			httpStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:       "unmapped error is internal and not leaked",
+			err:        errors.New("pq: password authentication failed for user admin"),
+			code:       codes.Internal,
+			message:    "internal error",
+			httpStatus: http.StatusInternalServerError,
+			logged:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			result := newMapper(logger).Resolve(context.Background(), tt.err)
+
+			assert.Equal(t, tt.code, result.Status.Code())
+			assert.Equal(t, tt.message, result.Status.Message())
+			assert.Equal(t, tt.httpStatus, result.HTTPStatus)
+			if tt.logged {
+				require.Len(t, logger.errors, 1)
+				assert.Equal(t, tt.err, logger.errors[0])
+			} else {
+				assert.Empty(t, logger.errors)
+			}
+		})
+	}
+}
+
+// TestResolveDetails verifies the google.rpc details attached to statuses
+func TestResolveDetails(t *testing.T) {
+	mapper := newMapper(&recordingLogger{})
+
+	result := mapper.Resolve(context.Background(), errUserNotFound)
+	require.Len(t, result.Status.Details(), 1)
+	info := result.Status.Details()[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, "USER_NOT_FOUND", info.Reason)
+	assert.Equal(t, "users.example.com", info.Domain)
+
+	err := errmap.InvalidArgument("invalid user", errmap.FieldViolation{Field: "email", Description: "must be an email address"})
+	result = mapper.Resolve(context.Background(), err)
+	require.Len(t, result.Status.Details(), 1)
+	violations := result.Status.Details()[0].(*errdetails.BadRequest).FieldViolations
+	require.Len(t, violations, 1)
+	assert.Equal(t, "email", violations[0].Field)
+
+	result = mapper.Resolve(context.Background(), errmap.Unavailable("maintenance", 30*time.Second))
+	require.Len(t, result.Status.Details(), 1)
+	assert.Equal(t, 30*time.Second, result.Status.Details()[0].(*errdetails.RetryInfo).RetryDelay.AsDuration())
+	assert.Equal(t, 30*time.Second, result.RetryAfter)
+}
@@ -0,0 +1,265 @@
+// Package client provides lifecycle-managed gRPC client connections.
+// Targets are configured by name under grpc.clients; connections are dialed
+// when first requested and closed when the application stops.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// Module provides the gRPC client connections to the application.
+var Module = fx.Module("grpc.client",
+	fx.Provide(
+		NewConfig,
+		NewConnections,
+	),
+)
+
+// ErrUnknownClient is returned when a client name is not configured under grpc.clients.
+var ErrUnknownClient = errors.New("grpc client not configured")
+
+// Config holds the configuration of every named client.
+type Config struct {
+	Clients map[string]ClientConfig `mapstructure:"clients"`
+}
+
+// ClientConfig holds the configuration of one client target.
+type ClientConfig struct {
+	Address        string          `mapstructure:"address"`         // Target, e.g. "users:50051" or "dns:///users:50051"
+	ConnectTimeout time.Duration   `mapstructure:"connect_timeout"` // If set, startup waits this long for the connection to be ready
+	Timeout        time.Duration   `mapstructure:"timeout"`         // Default deadline for calls without one (0 = none)
+	LoadBalancing  string          `mapstructure:"load_balancing"`  // pick_first (default) or round_robin
+	MaxRecvMsgSize int             `mapstructure:"max_recv_msg_size"`
+	MaxSendMsgSize int             `mapstructure:"max_send_msg_size"`
+	TLS            TLSConfig       `mapstructure:"tls"`
+	Retry          RetryConfig     `mapstructure:"retry"`
+	Keepalive      KeepaliveConfig `mapstructure:"keepalive"`
+}
+
+// TLSConfig holds the TLS settings of a client. Without TLS the connection is plaintext.
+type TLSConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CAFile             string `mapstructure:"ca_file"`   // PEM bundle verifying the server (default: system roots)
+	CertFile           string `mapstructure:"cert_file"` // Client certificate for mTLS
+	KeyFile            string `mapstructure:"key_file"`
+	ServerName         string `mapstructure:"server_name"` // Overrides the name verified in the server certificate
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+}
+
+// RetryConfig holds the retry policy applied to every method of a client.
+type RetryConfig struct {
+	MaxAttempts       int           `mapstructure:"max_attempts"` // Including the first call; retries are disabled below 2
+	InitialBackoff    time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff        time.Duration `mapstructure:"max_backoff"`
+	BackoffMultiplier float64       `mapstructure:"backoff_multiplier"`
+	RetryableCodes    []string      `mapstructure:"retryable_codes"` // e.g. ["UNAVAILABLE"]
+}
+
+// KeepaliveConfig holds the client keepalive settings. Zero values keep the gRPC defaults.
+type KeepaliveConfig struct {
+	Time                time.Duration `mapstructure:"time"`    // Ping the server after this long without activity
+	Timeout             time.Duration `mapstructure:"timeout"` // Close the connection if a ping is not acknowledged in time
+	PermitWithoutStream bool          `mapstructure:"permit_without_stream"`
+}
+
+// NewConfig creates the client configuration from Viper.
+func NewConfig(v *viper.Viper) *Config {
+	cfg := &Config{}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("grpc", cfg)
+	}
+
+	for name, c := range cfg.Clients {
+		if c.Retry.MaxAttempts > 1 {
+			if c.Retry.InitialBackoff == 0 {
+				c.Retry.InitialBackoff = 100 * time.Millisecond
+			}
+			if c.Retry.MaxBackoff == 0 {
+				c.Retry.MaxBackoff = time.Second
+			}
+			if c.Retry.BackoffMultiplier == 0 {
+				c.Retry.BackoffMultiplier = 2
+			}
+			if len(c.Retry.RetryableCodes) == 0 {
+				c.Retry.RetryableCodes = []string{"UNAVAILABLE"}
+			}
+		}
+		cfg.Clients[name] = c
+	}
+
+	return cfg
+}
+
+// ConnectionsParams contains the dependencies of NewConnections.
+type ConnectionsParams struct {
+	fx.In
+	Lifecycle fx.Lifecycle
+	Logger    log.Logger
+	Config    *Config
+	Options   []grpc.DialOption `group:"grpc.client_options"`
+}
+
+// Connections dials and owns the client connections of the application.
+type Connections struct {
+	cfg     *Config
+	options []grpc.DialOption
+	logger  log.Logger
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// NewConnections creates the connection registry. Connections requested
+// during construction are checked on start (if connect_timeout is set) and
+// all connections are closed on stop.
+func NewConnections(p ConnectionsParams) *Connections {
+	c := &Connections{
+		cfg:     p.Config,
+		options: p.Options,
+		logger:  p.Logger,
+		conns:   make(map[string]*grpc.ClientConn),
+	}
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: c.waitForReady,
+		OnStop: func(ctx context.Context) error {
+			return c.close()
+		},
+	})
+
+	return c
+}
+
+// Conn returns the connection of the named client, creating it on first use.
+// Creating a connection does not connect; it connects on the first call, or
+// on start if connect_timeout is set.
+func (c *Connections) Conn(name string) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if conn, ok := c.conns[name]; ok {
+		return conn, nil
+	}
+
+	cfg, ok := c.cfg.Clients[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownClient, name)
+	}
+
+	opts, err := dialOptions(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grpc.clients.%s: %w", name, err)
+	}
+	opts = append(opts, c.options...)
+
+	conn, err := grpc.NewClient(cfg.Address, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc client %s: %w", name, err)
+	}
+
+	c.logger.Info("Created gRPC client", log.Field{Key: "client", Value: name}, log.Field{Key: "address", Value: cfg.Address})
+	c.conns[name] = conn
+	return conn, nil
+}
+
+// waitForReady waits for connections with a connect_timeout to become ready.
+func (c *Connections) waitForReady(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range c.names() {
+		timeout := c.cfg.Clients[name].ConnectTimeout
+		if timeout <= 0 {
+			continue
+		}
+
+		conn := c.conns[name]
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		conn.Connect()
+		for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+			if !conn.WaitForStateChange(waitCtx, state) {
+				cancel()
+				return fmt.Errorf("grpc client %s not ready after %s (last state %s)", name, timeout, state)
+			}
+		}
+		cancel()
+	}
+	return nil
+}
+
+// close closes every connection.
+func (c *Connections) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for _, name := range c.names() {
+		if err := c.conns[name].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close grpc client %s: %w", name, err))
+		}
+		delete(c.conns, name)
+	}
+	return errors.Join(errs...)
+}
+
+// names returns the names of the dialed connections in a stable order.
+func (c *Connections) names() []string {
+	names := make([]string, 0, len(c.conns))
+	for name := range c.conns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AsGrpcClient provides a typed client stub for the named target configured
+// under grpc.clients.<name>. The constructor is usually a generated
+// pb.NewXClient function.
+//
+// Example:
+//
+//	client.AsGrpcClient(pb.NewUserServiceClient, "users")
+//
+//	// then inject pb.UserServiceClient anywhere
+//	func NewHandler(users pb.UserServiceClient) *Handler { ... }
+func AsGrpcClient[T any](constructor func(grpc.ClientConnInterface) T, name string) fx.Option {
+	return fx.Provide(func(c *Connections) (T, error) {
+		conn, err := c.Conn(name)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		return constructor(conn), nil
+	})
+}
+
+// AsDialOption contributes an extra grpc.DialOption, applied to every client
+// after the options built from the configuration. The constructor must return
+// a grpc.DialOption and may take dependencies from the Fx graph.
+//
+// Example:
+//
+//	client.AsDialOption(func() grpc.DialOption {
+//	    return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+//	})
+func AsDialOption(constructor any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			constructor,
+			fx.ResultTags(`group:"grpc.client_options"`),
+		),
+	)
+}
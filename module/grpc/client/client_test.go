@@ -0,0 +1,113 @@
+package client_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"github.com/things-kit/module/grpc/client"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+// freePort returns a TCP port that is currently free
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// TestAsGrpcClient verifies that typed stubs call the configured target
+func TestAsGrpcClient(t *testing.T) {
+	port := freePort(t)
+	server := viper.New()
+	server.Set("grpc.port", port)
+	serverApp := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(server),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		grpcmodule.Module,
+	)
+	serverApp.RequireStart()
+	defer serverApp.RequireStop()
+
+	v := viper.New()
+	v.Set("grpc.clients.health.address", "127.0.0.1:"+strconv.Itoa(port))
+	v.Set("grpc.clients.health.connect_timeout", "1s")
+	v.Set("grpc.clients.health.timeout", "1s")
+	v.Set("grpc.clients.health.load_balancing", "round_robin")
+	v.Set("grpc.clients.health.retry.max_attempts", 3)
+
+	var hc healthpb.HealthClient
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		client.Module,
+		client.AsGrpcClient(healthpb.NewHealthClient, "health"),
+		fx.Populate(&hc),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	resp, err := hc.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+// TestAsGrpcClientUnknownName verifies that unconfigured clients fail startup
+func TestAsGrpcClientUnknownName(t *testing.T) {
+	var hc healthpb.HealthClient
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(viper.New()),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		client.Module,
+		client.AsGrpcClient(healthpb.NewHealthClient, "missing"),
+		fx.Populate(&hc),
+	)
+	assert.ErrorIs(t, app.Err(), client.ErrUnknownClient)
+}
+
+// TestConnectTimeout verifies that startup fails when a target is unreachable
+func TestConnectTimeout(t *testing.T) {
+	v := viper.New()
+	v.Set("grpc.clients.down.address", "127.0.0.1:"+strconv.Itoa(freePort(t)))
+	v.Set("grpc.clients.down.connect_timeout", "100ms")
+
+	var hc healthpb.HealthClient
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		client.Module,
+		client.AsGrpcClient(healthpb.NewHealthClient, "down"),
+		fx.Populate(&hc),
+	)
+	require.NoError(t, app.Err())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Error(t, app.Start(ctx))
+}
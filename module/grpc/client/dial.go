@@ -0,0 +1,162 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// dialOptions maps a client configuration onto dial options.
+func dialOptions(cfg ClientConfig) ([]grpc.DialOption, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+
+	creds, err := transportCredentials(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceConfig, err := buildServiceConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+	}
+
+	var callOpts []grpc.CallOption
+	if cfg.MaxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	if len(callOpts) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+
+	if ka := cfg.Keepalive; ka.Time > 0 || ka.Timeout > 0 || ka.PermitWithoutStream {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                ka.Time,
+			Timeout:             ka.Timeout,
+			PermitWithoutStream: ka.PermitWithoutStream,
+		}))
+	}
+
+	return opts, nil
+}
+
+// transportCredentials builds TLS credentials from files, or plaintext credentials when TLS is disabled.
+func transportCredentials(cfg TLSConfig) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// serviceConfig is the JSON service config understood by grpc-go.
+// See https://github.com/grpc/grpc/blob/master/doc/service_config.md
+type serviceConfig struct {
+	LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig,omitempty"`
+	MethodConfig        []methodConfig        `json:"methodConfig,omitempty"`
+}
+
+type methodConfig struct {
+	Name        []struct{}   `json:"name"`
+	Timeout     string       `json:"timeout,omitempty"`
+	RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+// buildServiceConfig encodes the load balancing policy, default timeout and
+// retry policy of a client as a service config.
+func buildServiceConfig(cfg ClientConfig) (string, error) {
+	var sc serviceConfig
+
+	switch cfg.LoadBalancing {
+	case "", "pick_first":
+	case "round_robin":
+		sc.LoadBalancingConfig = []map[string]struct{}{{"round_robin": {}}}
+	default:
+		return "", fmt.Errorf("unsupported load_balancing %q", cfg.LoadBalancing)
+	}
+
+	// An empty name matches every method
+	method := methodConfig{Name: []struct{}{{}}}
+	if cfg.Timeout > 0 {
+		method.Timeout = duration(cfg.Timeout)
+	}
+	if r := cfg.Retry; r.MaxAttempts > 1 {
+		codes := make([]string, len(r.RetryableCodes))
+		for i, code := range r.RetryableCodes {
+			codes[i] = strings.ToUpper(code)
+		}
+		method.RetryPolicy = &retryPolicy{
+			MaxAttempts:          r.MaxAttempts,
+			InitialBackoff:       duration(r.InitialBackoff),
+			MaxBackoff:           duration(r.MaxBackoff),
+			BackoffMultiplier:    r.BackoffMultiplier,
+			RetryableStatusCodes: codes,
+		}
+	}
+	if method.Timeout != "" || method.RetryPolicy != nil {
+		sc.MethodConfig = []methodConfig{method}
+	}
+
+	b, err := json.Marshal(sc)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode service config: %w", err)
+	}
+	return string(b), nil
+}
+
+// duration formats d as a protobuf JSON duration.
+func duration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
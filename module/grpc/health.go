@@ -0,0 +1,168 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthConfig holds the settings of the grpc.health.v1 service.
+type HealthConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	CheckInterval time.Duration `mapstructure:"check_interval"` // How often registered health checks run
+	CheckTimeout  time.Duration `mapstructure:"check_timeout"`  // Timeout of a single check
+}
+
+// HealthCheck reports the health of one gRPC service.
+// Service is the fully-qualified service name (e.g. "users.v1.UserService"),
+// or "" for a check of the whole server.
+type HealthCheck struct {
+	Service string
+	Check   func(ctx context.Context) error
+}
+
+// AsHealthCheck registers a health check driving the status reported by the
+// grpc.health.v1 service. The constructor must return a HealthCheck.
+//
+// Example:
+//
+//	grpcmodule.AsHealthCheck(func(db *sql.DB) grpcmodule.HealthCheck {
+//	    return grpcmodule.HealthCheck{Service: "users.v1.UserService", Check: db.PingContext}
+//	})
+func AsHealthCheck(constructor any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			constructor,
+			fx.ResultTags(`group:"grpc.health_checks"`),
+		),
+	)
+}
+
+// NewHealthServer creates the grpc.health.v1 implementation registered on the server.
+// Inject it to set statuses manually.
+func NewHealthServer() *health.Server {
+	return health.NewServer()
+}
+
+// healthMonitor drives service statuses from the server lifecycle and the registered checks.
+// Services are SERVING while the server runs unless one of their checks fails; the
+// overall status ("") is SERVING only while every check passes.
+type healthMonitor struct {
+	server   *health.Server
+	checks   []HealthCheck
+	services []string
+	cfg      HealthConfig
+	logger   log.Logger
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+// newHealthMonitor tracks the services registered on server, which must
+// already include the health service. It fails if checks are registered but
+// the check interval or timeout is not positive.
+func newHealthMonitor(server *grpc.Server, hs *health.Server, cfg HealthConfig, checks []HealthCheck, logger log.Logger) (*healthMonitor, error) {
+	if len(checks) > 0 && cfg.CheckInterval <= 0 {
+		return nil, fmt.Errorf("grpc.health.check_interval must be positive when health checks are registered")
+	}
+	if len(checks) > 0 && cfg.CheckTimeout <= 0 {
+		return nil, fmt.Errorf("grpc.health.check_timeout must be positive when health checks are registered")
+	}
+
+	m := &healthMonitor{server: hs, checks: checks, cfg: cfg, logger: logger}
+	known := make(map[string]bool)
+	for name := range server.GetServiceInfo() {
+		known[name] = true
+	}
+	for _, check := range checks {
+		if check.Service != "" {
+			known[check.Service] = true
+		}
+	}
+	for name := range known {
+		m.services = append(m.services, name)
+	}
+
+	// Not serving until started
+	m.setAll(healthpb.HealthCheckResponse_NOT_SERVING)
+	return m, nil
+}
+
+// start marks all services as serving and starts running the checks.
+func (m *healthMonitor) start() {
+	m.setAll(healthpb.HealthCheckResponse_SERVING)
+	if len(m.checks) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.runChecks(ctx)
+
+	m.done.Add(1)
+	go func() {
+		defer m.done.Done()
+		ticker := time.NewTicker(m.cfg.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.runChecks(ctx)
+			}
+		}
+	}()
+}
+
+// shutdown marks every service as not serving and stops the checks.
+// Statuses cannot change afterwards.
+func (m *healthMonitor) shutdown() {
+	m.server.Shutdown()
+	if m.cancel != nil {
+		m.cancel()
+		m.done.Wait()
+	}
+}
+
+// runChecks runs every check once and updates the statuses.
+func (m *healthMonitor) runChecks(ctx context.Context) {
+	failing := make(map[string]bool)
+	for _, check := range m.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, m.cfg.CheckTimeout)
+		err := check.Check(checkCtx)
+		cancel()
+		if err != nil {
+			failing[check.Service] = true
+			m.logger.WarnC(ctx, "gRPC health check failed", err, log.Field{Key: "service", Value: check.Service})
+		}
+	}
+
+	for _, name := range m.services {
+		m.server.SetServingStatus(name, servingStatus(!failing[name]))
+	}
+	m.server.SetServingStatus("", servingStatus(len(failing) == 0))
+}
+
+// setAll sets the status of the server and every registered service.
+func (m *healthMonitor) setAll(status healthpb.HealthCheckResponse_ServingStatus) {
+	m.server.SetServingStatus("", status)
+	for _, name := range m.services {
+		m.server.SetServingStatus(name, status)
+	}
+}
+
+// servingStatus converts a health result into a serving status.
+func servingStatus(healthy bool) healthpb.HealthCheckResponse_ServingStatus {
+	if healthy {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
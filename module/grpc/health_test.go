@@ -0,0 +1,115 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+// newTestApp returns an app running the gRPC module on a random port
+func newTestApp(t *testing.T, opts ...fx.Option) *fxtest.App {
+	v := viper.New()
+	v.Set("grpc.port", 0)
+	return fxtest.New(t, append([]fx.Option{
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		grpcmodule.Module,
+	}, opts...)...)
+}
+
+// servingStatus queries the health server directly
+func servingStatus(t *testing.T, hs *health.Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	resp, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.Status
+}
+
+// TestHealthFollowsLifecycle verifies that the server reports SERVING only while running
+func TestHealthFollowsLifecycle(t *testing.T) {
+	var hs *health.Server
+	app := newTestApp(t, fx.Populate(&hs))
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, hs, ""))
+	app.RequireStart()
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, hs, ""))
+	app.RequireStop()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, hs, ""))
+}
+
+// TestHealthChecks verifies that a failing check marks its service and the server NOT_SERVING
+func TestHealthChecks(t *testing.T) {
+	var hs *health.Server
+	app := newTestApp(t,
+		fx.Populate(&hs),
+		grpcmodule.AsHealthCheck(func() grpcmodule.HealthCheck {
+			return grpcmodule.HealthCheck{Service: "users.v1.UserService", Check: func(context.Context) error {
+				return errors.New("database unreachable")
+			}}
+		}),
+		grpcmodule.AsHealthCheck(func() grpcmodule.HealthCheck {
+			return grpcmodule.HealthCheck{Service: "orders.v1.OrderService", Check: func(context.Context) error {
+				return nil
+			}}
+		}),
+	)
+
+	app.RequireStart()
+	defer app.RequireStop()
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, hs, "users.v1.UserService"))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, hs, "orders.v1.OrderService"))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, hs, ""))
+}
+
+// TestHealthCheckConfig verifies that checks cannot be scheduled with a non-positive interval or timeout
+func TestHealthCheckConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr string
+	}{
+		{name: "zero interval", key: "grpc.health.check_interval", wantErr: "grpc.health.check_interval must be positive"},
+		{name: "zero timeout", key: "grpc.health.check_timeout", wantErr: "grpc.health.check_timeout must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := viper.New()
+			v.Set(tt.key, "0s")
+			err := fx.New(
+				fx.NopLogger,
+				fx.Supply(v),
+				fx.Provide(func() log.Logger { return nopLogger{} }),
+				grpcmodule.Module,
+				grpcmodule.AsHealthCheck(func() grpcmodule.HealthCheck {
+					return grpcmodule.HealthCheck{Check: func(context.Context) error { return nil }}
+				}),
+			).Err()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Orders of the built-in interceptors. Interceptors run in ascending order, so
+// the first one is the outermost; interceptors with equal order run in
+// registration order. OrderErrors, OrderAuth, OrderAuthorization and
+// OrderValidation are the orders of the interceptors contributed by the
+// errmap, auth, authz and validation modules. Use an order below
+// OrderRecovery only for interceptors that must see calls before panics are
+// recovered.
+const (
+	OrderRecovery      = 100
+	OrderLogging       = 200
+	OrderErrors        = 250
+	OrderDeadline      = 300
+	OrderAuth          = 350
+	OrderAuthorization = 375
+	OrderValidation    = 400
+
+	// OrderDefault is a suggested order for application interceptors,
+	// running inside all built-in interceptors.
+	OrderDefault = 1000
+)
+
+// InterceptorsConfig holds the settings of the built-in interceptors.
+type InterceptorsConfig struct {
+	Recovery       bool          `mapstructure:"recovery"`        // Convert handler panics into codes.Internal
+	Logging        bool          `mapstructure:"logging"`         // Log every call through log.Logger
+	DefaultTimeout time.Duration `mapstructure:"default_timeout"` // Deadline for calls that have none (0 = none)
+	MaxTimeout     time.Duration `mapstructure:"max_timeout"`     // Upper bound on client deadlines (0 = unbounded)
+}
+
+// UnaryInterceptor is an ordered unary server interceptor contributed with AsUnaryInterceptor.
+type UnaryInterceptor struct {
+	Order       int
+	Interceptor grpc.UnaryServerInterceptor
+}
+
+// StreamInterceptor is an ordered stream server interceptor contributed with AsStreamInterceptor.
+type StreamInterceptor struct {
+	Order       int
+	Interceptor grpc.StreamServerInterceptor
+}
+
+var (
+	unaryInterceptorType  = reflect.TypeOf(grpc.UnaryServerInterceptor(nil))
+	streamInterceptorType = reflect.TypeOf(grpc.StreamServerInterceptor(nil))
+	errorType             = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// AsUnaryInterceptor registers a unary server interceptor at the given order.
+// The constructor may take any dependencies from the Fx graph and must return
+// a grpc.UnaryServerInterceptor, optionally with an error.
+//
+// Example:
+//
+//	grpcmodule.AsUnaryInterceptor(func(limiter ratelimit.Limiter, logger log.Logger) (grpc.UnaryServerInterceptor, error) {
+//	    return ratelimitgrpc.UnaryServerInterceptor(limiter, logger, "api", ratelimit.PerSecond(50), ratelimitgrpc.ByPeer())
+//	}, grpcmodule.OrderDefault)
+func AsUnaryInterceptor(constructor any, order int) fx.Option {
+	return provideInterceptor(constructor, unaryInterceptorType, `group:"grpc.unary_interceptors"`,
+		func(v reflect.Value) any {
+			return UnaryInterceptor{Order: order, Interceptor: v.Interface().(grpc.UnaryServerInterceptor)}
+		})
+}
+
+// AsStreamInterceptor registers a stream server interceptor at the given order.
+// The constructor may take any dependencies from the Fx graph and must return
+// a grpc.StreamServerInterceptor, optionally with an error.
+func AsStreamInterceptor(constructor any, order int) fx.Option {
+	return provideInterceptor(constructor, streamInterceptorType, `group:"grpc.stream_interceptors"`,
+		func(v reflect.Value) any {
+			return StreamInterceptor{Order: order, Interceptor: v.Interface().(grpc.StreamServerInterceptor)}
+		})
+}
+
+// provideInterceptor provides a function with the constructor's parameters that
+// calls the constructor and wraps its interceptor into the group's element type.
+func provideInterceptor(constructor any, target reflect.Type, group string, wrap func(reflect.Value) any) fx.Option {
+	ctor := reflect.ValueOf(constructor)
+	ct := ctor.Type()
+	if ct.Kind() != reflect.Func || ct.NumOut() < 1 || ct.NumOut() > 2 ||
+		!ct.Out(0).ConvertibleTo(target) || (ct.NumOut() == 2 && ct.Out(1) != errorType) {
+		return fx.Error(fmt.Errorf("interceptor constructor must be a func returning %s and optionally an error, got %T", target, constructor))
+	}
+
+	in := make([]reflect.Type, ct.NumIn())
+	for i := range in {
+		in[i] = ct.In(i)
+	}
+	element := reflect.TypeOf(wrap(reflect.Zero(target)))
+	fn := reflect.MakeFunc(reflect.FuncOf(in, []reflect.Type{element, errorType}, ct.IsVariadic()),
+		func(args []reflect.Value) []reflect.Value {
+			var out []reflect.Value
+			if ct.IsVariadic() {
+				out = ctor.CallSlice(args)
+			} else {
+				out = ctor.Call(args)
+			}
+			if len(out) == 2 && !out[1].IsNil() {
+				return []reflect.Value{reflect.Zero(element), out[1]}
+			}
+			return []reflect.Value{reflect.ValueOf(wrap(out[0].Convert(target))), reflect.Zero(errorType)}
+		})
+
+	return fx.Provide(
+		fx.Annotate(
+			fn.Interface(),
+			fx.ResultTags(group),
+		),
+	)
+}
+
+// buildInterceptors returns the server options chaining the built-in interceptors
+// enabled in cfg and the contributed ones, sorted by order.
+func buildInterceptors(cfg InterceptorsConfig, logger log.Logger, unary []UnaryInterceptor, stream []StreamInterceptor) []grpc.ServerOption {
+	var builtinUnary []UnaryInterceptor
+	var builtinStream []StreamInterceptor
+	if cfg.Recovery {
+		builtinUnary = append(builtinUnary, UnaryInterceptor{Order: OrderRecovery, Interceptor: RecoveryUnaryInterceptor(logger)})
+		builtinStream = append(builtinStream, StreamInterceptor{Order: OrderRecovery, Interceptor: RecoveryStreamInterceptor(logger)})
+	}
+	if cfg.Logging {
+		builtinUnary = append(builtinUnary, UnaryInterceptor{Order: OrderLogging, Interceptor: LoggingUnaryInterceptor(logger)})
+		builtinStream = append(builtinStream, StreamInterceptor{Order: OrderLogging, Interceptor: LoggingStreamInterceptor(logger)})
+	}
+	if cfg.DefaultTimeout > 0 || cfg.MaxTimeout > 0 {
+		builtinUnary = append(builtinUnary, UnaryInterceptor{Order: OrderDeadline, Interceptor: DeadlineUnaryInterceptor(cfg.DefaultTimeout, cfg.MaxTimeout)})
+		builtinStream = append(builtinStream, StreamInterceptor{Order: OrderDeadline, Interceptor: DeadlineStreamInterceptor(cfg.DefaultTimeout, cfg.MaxTimeout)})
+	}
+
+	unary = append(builtinUnary, unary...)
+	stream = append(builtinStream, stream...)
+	sort.SliceStable(unary, func(i, j int) bool { return unary[i].Order < unary[j].Order })
+	sort.SliceStable(stream, func(i, j int) bool { return stream[i].Order < stream[j].Order })
+
+	unaryChain := make([]grpc.UnaryServerInterceptor, len(unary))
+	for i, u := range unary {
+		unaryChain[i] = u.Interceptor
+	}
+	streamChain := make([]grpc.StreamServerInterceptor, len(stream))
+	for i, s := range stream {
+		streamChain[i] = s.Interceptor
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryChain...),
+		grpc.ChainStreamInterceptor(streamChain...),
+	}
+}
+
+// RecoveryUnaryInterceptor converts panics in handlers into codes.Internal errors
+// and logs them with their stack trace. Panic values are never sent to clients.
+func RecoveryUnaryInterceptor(logger log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, logger, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamInterceptor converts panics in stream handlers into codes.Internal errors.
+func RecoveryStreamInterceptor(logger log.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), logger, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recovered logs a recovered panic and returns the error sent to the client.
+func recovered(ctx context.Context, logger log.Logger, method string, r any) error {
+	logger.ErrorC(ctx, "gRPC handler panic", fmt.Errorf("panic: %v", r),
+		log.Field{Key: "method", Value: method},
+		log.Field{Key: "stack", Value: string(debug.Stack())},
+	)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// LoggingUnaryInterceptor logs every call with its method, status code, duration and peer.
+// Calls failing with a server-side code are logged as errors.
+func LoggingUnaryInterceptor(logger log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// LoggingStreamInterceptor logs every stream with its method, status code, duration and peer.
+func LoggingStreamInterceptor(logger log.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), logger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// logCall logs the outcome of a call.
+func logCall(ctx context.Context, logger log.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	fields := []log.Field{
+		{Key: "method", Value: method},
+		{Key: "code", Value: code.String()},
+		{Key: "duration", Value: time.Since(start)},
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, log.Field{Key: "peer", Value: p.Addr.String()})
+	}
+
+	switch code {
+	case codes.OK:
+		logger.InfoC(ctx, "gRPC call", fields...)
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		logger.ErrorC(ctx, "gRPC call failed", err, fields...)
+	default:
+		logger.WarnC(ctx, "gRPC call failed", err, fields...)
+	}
+}
+
+// DeadlineUnaryInterceptor applies defaultTimeout to calls without a deadline and
+// caps client deadlines at maxTimeout. Either may be 0 to disable it; the
+// default is capped at maxTimeout too.
+// Calls whose deadline has already passed are rejected with codes.DeadlineExceeded.
+func DeadlineUnaryInterceptor(defaultTimeout, maxTimeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, cancel, err := withDeadline(ctx, defaultTimeout, maxTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// DeadlineStreamInterceptor applies the same deadline rules as DeadlineUnaryInterceptor to streams.
+func DeadlineStreamInterceptor(defaultTimeout, maxTimeout time.Duration) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel, err := withDeadline(ss.Context(), defaultTimeout, maxTimeout)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// withDeadline derives the context a call runs under.
+func withDeadline(ctx context.Context, defaultTimeout, maxTimeout time.Duration) (context.Context, context.CancelFunc, error) {
+	deadline, ok := ctx.Deadline()
+	if ok && !time.Now().Before(deadline) {
+		return nil, nil, status.Error(codes.DeadlineExceeded, "deadline exceeded before the call started")
+	}
+
+	switch {
+	case !ok && defaultTimeout > 0:
+		if maxTimeout > 0 {
+			defaultTimeout = min(defaultTimeout, maxTimeout)
+		}
+		ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+		return ctx, cancel, nil
+	case maxTimeout > 0 && (!ok || time.Until(deadline) > maxTimeout):
+		ctx, cancel := context.WithTimeout(ctx, maxTimeout)
+		return ctx, cancel, nil
+	}
+	return ctx, func() {}, nil
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the overridden context.
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
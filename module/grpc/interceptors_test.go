@@ -0,0 +1,94 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestAsUnaryInterceptor verifies that constructors are resolved from the graph and keep their order
+func TestAsUnaryInterceptor(t *testing.T) {
+	type params struct {
+		fx.In
+		Interceptors []grpcmodule.UnaryInterceptor `group:"grpc.unary_interceptors"`
+	}
+
+	var got params
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply("tenant"),
+		grpcmodule.AsUnaryInterceptor(func(name string) grpc.UnaryServerInterceptor {
+			return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				return handler(ctx, name)
+			}
+		}, grpcmodule.OrderDefault),
+		grpcmodule.AsUnaryInterceptor(func() (grpc.UnaryServerInterceptor, error) {
+			return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				return handler(ctx, req)
+			}, nil
+		}, 50),
+		fx.Invoke(func(p params) { got = p }),
+	)
+	require.NoError(t, app.Err())
+	require.Len(t, got.Interceptors, 2)
+
+	orders := []int{got.Interceptors[0].Order, got.Interceptors[1].Order}
+	assert.ElementsMatch(t, []int{grpcmodule.OrderDefault, 50}, orders)
+
+	for _, i := range got.Interceptors {
+		if i.Order == grpcmodule.OrderDefault {
+			resp, err := i.Interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(_ context.Context, req any) (any, error) {
+				return req, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "tenant", resp)
+		}
+	}
+}
+
+// TestAsUnaryInterceptorRejectsBadConstructor verifies that a constructor of the wrong type fails the app
+func TestAsUnaryInterceptorRejectsBadConstructor(t *testing.T) {
+	app := fx.New(fx.NopLogger, grpcmodule.AsUnaryInterceptor(func() string { return "" }, 0))
+	assert.Error(t, app.Err())
+}
+
+// TestDeadlineUnaryInterceptor verifies default and maximum deadlines
+func TestDeadlineUnaryInterceptor(t *testing.T) {
+	interceptor := grpcmodule.DeadlineUnaryInterceptor(time.Second, time.Minute)
+	remaining := func(ctx context.Context, interceptor grpc.UnaryServerInterceptor) time.Duration {
+		var d time.Duration
+		_, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+			deadline, ok := ctx.Deadline()
+			require.True(t, ok)
+			d = time.Until(deadline)
+			return nil, nil
+		})
+		return d
+	}
+
+	assert.InDelta(t, time.Second, remaining(context.Background(), interceptor), float64(100*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	assert.InDelta(t, time.Minute, remaining(ctx, interceptor), float64(100*time.Millisecond))
+
+	capped := grpcmodule.DeadlineUnaryInterceptor(time.Hour, time.Minute)
+	assert.InDelta(t, time.Minute, remaining(context.Background(), capped), float64(100*time.Millisecond),
+		"the default timeout is capped at the maximum")
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, err := interceptor(expired, nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return nil, errors.New("handler must not run")
+	})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
@@ -5,43 +5,88 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/tlsreload"
 	"go.uber.org/fx"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 // Module provides the gRPC server module to the application.
 var Module = fx.Module("grpc",
-	fx.Provide(NewConfig),
+	fx.Provide(
+		NewConfig,
+		NewHealthServer,
+	),
 	fx.Invoke(RunGrpcServer),
 )
 
 // Config holds the gRPC server configuration.
 type Config struct {
-	Port int `mapstructure:"port"`
-}
-
-// serviceBinding holds a service implementation and its registrar function.
-type serviceBinding struct {
-	impl      any
-	registrar any
+	Host       string `mapstructure:"host"` // Interface to bind to (empty = all interfaces)
+	Port       int    `mapstructure:"port"`
+	Reflection bool   `mapstructure:"reflection"` // Register the server reflection service
+
+	// ShutdownDelay is how long the server keeps serving after reporting NOT_SERVING,
+	// giving load balancers time to stop routing new calls before connections drain.
+	ShutdownDelay time.Duration `mapstructure:"shutdown_delay"`
+
+	// Tuning; zero values keep the gRPC defaults
+	MaxRecvMsgSize       int             `mapstructure:"max_recv_msg_size"`      // Bytes (gRPC default 4MB)
+	MaxSendMsgSize       int             `mapstructure:"max_send_msg_size"`      // Bytes (gRPC default unlimited)
+	MaxConcurrentStreams uint32          `mapstructure:"max_concurrent_streams"` // Per connection
+	ConnectionTimeout    time.Duration   `mapstructure:"connection_timeout"`     // Handshake timeout for new connections
+	ReadBufferSize       int             `mapstructure:"read_buffer_size"`       // Bytes
+	WriteBufferSize      int             `mapstructure:"write_buffer_size"`      // Bytes
+	NumStreamWorkers     uint32          `mapstructure:"num_stream_workers"`     // Worker goroutines for incoming streams (0 = one goroutine per stream)
+	Keepalive            KeepaliveConfig `mapstructure:"keepalive"`
+
+	TLS          TLSConfig          `mapstructure:"tls"`
+	Health       HealthConfig       `mapstructure:"health"`
+	Interceptors InterceptorsConfig `mapstructure:"interceptors"`
 }
 
 // GrpcServerParams contains all dependencies needed to run the gRPC server.
 type GrpcServerParams struct {
 	fx.In
-	Lifecycle fx.Lifecycle
-	Logger    log.Logger
-	Config    *Config
-	Services  []serviceBinding `group:"grpc.services"`
+	Lifecycle  fx.Lifecycle
+	Shutdowner fx.Shutdowner
+	Logger     log.Logger
+	Config     *Config
+	Services   []serviceBinding `group:"grpc.services"`
+	Health     *health.Server
+	Checks     []HealthCheck `group:"grpc.health_checks"`
+
+	// Listener replaces the listener on grpc.host/grpc.port, e.g. to share a port with HTTP
+	Listener net.Listener `name:"grpc.listener" optional:"true"`
+
+	UnaryInterceptors  []UnaryInterceptor  `group:"grpc.unary_interceptors"`
+	StreamInterceptors []StreamInterceptor `group:"grpc.stream_interceptors"`
+	Options            []grpc.ServerOption `group:"grpc.server_options"`
 }
 
 // NewConfig creates a new gRPC configuration from Viper.
 func NewConfig(v *viper.Viper) *Config {
 	cfg := &Config{
 		Port: 50051, // Default port
+		TLS: TLSConfig{
+			ReloadInterval: 30 * time.Second,
+		},
+		Health: HealthConfig{
+			Enabled:       true,
+			CheckInterval: 10 * time.Second,
+			CheckTimeout:  2 * time.Second,
+		},
+		Interceptors: InterceptorsConfig{
+			Recovery: true,
+			Logging:  true,
+		},
 	}
 
 	// Load configuration from viper
@@ -53,64 +98,135 @@ func NewConfig(v *viper.Viper) *Config {
 }
 
 // RunGrpcServer starts the gRPC server with registered services.
-func RunGrpcServer(p GrpcServerParams) {
-	server := grpc.NewServer()
-
-	// Register all provided services
-	for _, binding := range p.Services {
-		// Call the registrar function to register the service implementation
-		// The registrar is a function like: func RegisterUserServiceServer(s grpc.ServiceRegistrar, srv UserServiceServer)
-		if registrarFunc, ok := binding.registrar.(func(grpc.ServiceRegistrar, any)); ok {
-			registrarFunc(server, binding.impl)
+// It also registers the grpc.health.v1 service (grpc.health.enabled) and server
+// reflection (grpc.reflection). The built-in interceptors enabled under
+// grpc.interceptors and those contributed with AsUnaryInterceptor and
+// AsStreamInterceptor are chained by order. With grpc.tls.enabled the server
+// only accepts TLS connections, reloading its certificates when they change.
+// Options contributed with AsServerOption are applied last.
+//
+// On stop, in-flight calls are drained until the OnStop context expires, after
+// which remaining connections are closed. If the server fails while running,
+// the application is shut down.
+func RunGrpcServer(p GrpcServerParams) error {
+	opts := serverOptions(p.Config)
+	opts = append(opts, buildInterceptors(p.Config.Interceptors, p.Logger, p.UnaryInterceptors, p.StreamInterceptors)...)
+
+	var certs *tlsreload.Reloader
+	if p.Config.TLS.Enabled {
+		if p.Listener != nil {
+			return fmt.Errorf("grpc.tls cannot be used on a shared listener: terminate TLS in front of the shared port")
+		}
+		var err error
+		if certs, err = tlsreload.NewReloader("grpc.tls", p.Config.TLS, p.Logger); err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(serverCredentials(certs)))
+	}
+
+	opts = append(opts, p.Options...)
+	server := grpc.NewServer(opts...)
+
+	// Register all provided services, including the health service so that
+	// duplicates are reported instead of aborting the process
+	services := p.Services
+	if p.Config.Health.Enabled {
+		services = append(services, serviceBinding{
+			name:     "grpc.health.v1",
+			register: func(s grpc.ServiceRegistrar) { healthpb.RegisterHealthServer(s, p.Health) },
+		})
+	}
+	if err := registerServices(server, services); err != nil {
+		return err
+	}
+
+	if p.Config.Reflection {
+		reflection.Register(server)
+	}
+
+	var monitor *healthMonitor
+	if p.Config.Health.Enabled {
+		var err error
+		if monitor, err = newHealthMonitor(server, p.Health, p.Config.Health, p.Checks, p.Logger); err != nil {
+			return err
 		}
 	}
 
-	addr := fmt.Sprintf(":%d", p.Config.Port)
+	addr := net.JoinHostPort(p.Config.Host, strconv.Itoa(p.Config.Port))
+	if p.Listener != nil {
+		addr = p.Listener.Addr().String()
+	}
 
 	p.Lifecycle.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			listener, err := net.Listen("tcp", addr)
-			if err != nil {
-				return fmt.Errorf("failed to listen on %s: %w", addr, err)
+			listener := p.Listener
+			if listener == nil {
+				var err error
+				if listener, err = net.Listen("tcp", addr); err != nil {
+					return fmt.Errorf("failed to listen on %s: %w", addr, err)
+				}
 			}
 
-			p.Logger.Info("Starting gRPC server", log.Field{Key: "address", Value: addr})
+			p.Logger.Info("Starting gRPC server", log.Field{Key: "address", Value: addr}, log.Field{Key: "tls", Value: certs != nil})
+
+			if certs != nil {
+				certs.Start()
+			}
 
 			go func() {
 				if err := server.Serve(listener); err != nil {
 					p.Logger.Error("gRPC server error", err, log.Field{Key: "address", Value: addr})
+					if err := p.Shutdowner.Shutdown(fx.ExitCode(1)); err != nil {
+						p.Logger.Error("Failed to shut down application", err)
+					}
 				}
 			}()
 
+			if monitor != nil {
+				monitor.start()
+			}
+
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
 			p.Logger.Info("Stopping gRPC server", log.Field{Key: "address", Value: addr})
-			server.GracefulStop()
+
+			// Report NOT_SERVING so that load balancers stop routing before connections drain
+			if monitor != nil {
+				monitor.shutdown()
+			}
+			if p.Config.ShutdownDelay > 0 {
+				select {
+				case <-time.After(p.Config.ShutdownDelay):
+				case <-ctx.Done():
+				}
+			}
+			stopServer(ctx, server, p.Logger)
+
+			if certs != nil {
+				certs.Close()
+			}
 			return nil
 		},
 	})
+
+	return nil
 }
 
-// AsGrpcService is a generic helper to register a gRPC service implementation.
-// It takes a constructor function that creates the service and a registrar function
-// that registers the service with the gRPC server.
-//
-// Example:
-//
-//	grpcmodule.AsGrpcService(service.NewUserService, pb.RegisterUserServiceServer)
-func AsGrpcService(constructor any, registrar any) fx.Option {
-	return fx.Provide(
-		fx.Annotate(
-			func(impl any) serviceBinding {
-				return serviceBinding{impl: impl, registrar: registrar}
-			},
-			fx.ParamTags(`name:"service_impl"`),
-			fx.ResultTags(`group:"grpc.services"`),
-		),
-		fx.Annotate(
-			constructor,
-			fx.ResultTags(`name:"service_impl"`),
-		),
-	)
+// stopServer stops the server gracefully, closing remaining connections
+// once ctx expires.
+func stopServer(ctx context.Context, server *grpc.Server, logger log.Logger) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Warn("gRPC graceful stop timed out, closing remaining connections")
+		server.Stop()
+		<-done
+	}
 }
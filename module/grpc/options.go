@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"time"
+
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// KeepaliveConfig holds the keepalive settings of the gRPC server.
+// Zero values keep the gRPC defaults.
+type KeepaliveConfig struct {
+	// Server parameters
+	Time                  time.Duration `mapstructure:"time"`                     // Ping idle clients after this long
+	Timeout               time.Duration `mapstructure:"timeout"`                  // Close the connection if a ping is not acknowledged in time
+	MaxConnectionIdle     time.Duration `mapstructure:"max_connection_idle"`      // Close connections idle for this long
+	MaxConnectionAge      time.Duration `mapstructure:"max_connection_age"`       // Close connections older than this, so clients rebalance
+	MaxConnectionAgeGrace time.Duration `mapstructure:"max_connection_age_grace"` // Time given to in-flight calls after max_connection_age
+
+	// Enforcement policy
+	MinTime             time.Duration `mapstructure:"min_time"`              // Minimum interval between client pings
+	PermitWithoutStream bool          `mapstructure:"permit_without_stream"` // Allow client pings without active streams
+}
+
+// AsServerOption contributes an extra grpc.ServerOption, applied after the
+// options built from the grpc configuration. The constructor must return a
+// grpc.ServerOption and may take dependencies from the Fx graph.
+//
+// Example:
+//
+//	grpcmodule.AsServerOption(func() grpc.ServerOption {
+//	    return grpc.StatsHandler(otelgrpc.NewServerHandler())
+//	})
+func AsServerOption(constructor any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			constructor,
+			fx.ResultTags(`group:"grpc.server_options"`),
+		),
+	)
+}
+
+// serverOptions maps the tuning settings of cfg onto server options.
+func serverOptions(cfg *Config) []grpc.ServerOption {
+	var opts []grpc.ServerOption
+
+	if cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	if cfg.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(cfg.MaxConcurrentStreams))
+	}
+	if cfg.ConnectionTimeout > 0 {
+		opts = append(opts, grpc.ConnectionTimeout(cfg.ConnectionTimeout))
+	}
+	if cfg.ReadBufferSize > 0 {
+		opts = append(opts, grpc.ReadBufferSize(cfg.ReadBufferSize))
+	}
+	if cfg.WriteBufferSize > 0 {
+		opts = append(opts, grpc.WriteBufferSize(cfg.WriteBufferSize))
+	}
+	if cfg.NumStreamWorkers > 0 {
+		opts = append(opts, grpc.NumStreamWorkers(cfg.NumStreamWorkers))
+	}
+
+	ka := cfg.Keepalive
+	if ka.Time > 0 || ka.Timeout > 0 || ka.MaxConnectionIdle > 0 || ka.MaxConnectionAge > 0 || ka.MaxConnectionAgeGrace > 0 {
+		opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:                  ka.Time,
+			Timeout:               ka.Timeout,
+			MaxConnectionIdle:     ka.MaxConnectionIdle,
+			MaxConnectionAge:      ka.MaxConnectionAge,
+			MaxConnectionAgeGrace: ka.MaxConnectionAgeGrace,
+		}))
+	}
+	if ka.MinTime > 0 || ka.PermitWithoutStream {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             ka.MinTime,
+			PermitWithoutStream: ka.PermitWithoutStream,
+		}))
+	}
+
+	return opts
+}
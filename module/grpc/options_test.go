@@ -0,0 +1,64 @@
+package grpc_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// TestServerOptions verifies host binding, tuning settings and contributed options
+func TestServerOptions(t *testing.T) {
+	port := freePort(t)
+	v := viper.New()
+	v.Set("grpc.host", "127.0.0.1")
+	v.Set("grpc.port", port)
+	v.Set("grpc.max_recv_msg_size", 1024)
+	v.Set("grpc.keepalive.max_connection_age", "1m")
+
+	var calls atomic.Int32
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		grpcmodule.Module,
+		grpcmodule.AsServerOption(func() grpc.ServerOption {
+			return grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				calls.Add(1)
+				return handler(ctx, req)
+			})
+		}),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	conn, err := grpc.Dial("127.0.0.1:"+strconv.Itoa(port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: strings.Repeat("x", 2048)})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,131 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+)
+
+// serviceBinding registers one service implementation on the server.
+type serviceBinding struct {
+	name     string // Registrar function name, for errors
+	register func(grpc.ServiceRegistrar)
+}
+
+// serviceCount makes the name tags of service implementations unique.
+var serviceCount atomic.Int64
+
+// AsGrpcService registers a gRPC service implementation. The registrar is the
+// generated pb.RegisterXServer function and its server interface T is inferred
+// from it. The constructor takes its dependencies as a single parameter, one
+// value from the Fx graph or an fx.In parameter struct, and returns a T, so a
+// constructor that does not build the registrar's service does not compile.
+//
+// Example:
+//
+//	// func NewUserService(p UserServiceParams) pb.UserServiceServer
+//	grpcmodule.AsGrpcService(service.NewUserService, pb.RegisterUserServiceServer)
+func AsGrpcService[T, P any](constructor func(P) T, registrar func(grpc.ServiceRegistrar, T)) fx.Option {
+	return provideService(constructor, registrar)
+}
+
+// AsGrpcServiceE is AsGrpcService for constructors that can fail. An error
+// returned by the constructor fails application startup.
+//
+// Example:
+//
+//	// func NewUserService(p UserServiceParams) (pb.UserServiceServer, error)
+//	grpcmodule.AsGrpcServiceE(service.NewUserService, pb.RegisterUserServiceServer)
+func AsGrpcServiceE[T, P any](constructor func(P) (T, error), registrar func(grpc.ServiceRegistrar, T)) fx.Option {
+	return provideService(constructor, registrar)
+}
+
+// provideService provides the implementation built by constructor under a
+// unique name and a binding registering it with registrar.
+func provideService[T any](constructor any, registrar func(grpc.ServiceRegistrar, T)) fx.Option {
+	name := funcName(registrar)
+	tag := fmt.Sprintf(`name:"grpc.service.%d"`, serviceCount.Add(1))
+	return fx.Provide(
+		fx.Annotate(
+			constructor,
+			fx.ResultTags(tag),
+		),
+		fx.Annotate(
+			func(impl T) serviceBinding {
+				return serviceBinding{
+					name:     name,
+					register: func(s grpc.ServiceRegistrar) { registrar(s, impl) },
+				}
+			},
+			fx.ParamTags(tag),
+			fx.ResultTags(`group:"grpc.services"`),
+		),
+	)
+}
+
+// registerServices registers every binding, failing on bindings that register
+// nothing, register an implementation missing methods, or register a service twice.
+func registerServices(server *grpc.Server, bindings []serviceBinding) error {
+	registered := make(map[string]string)
+	var errs []error
+	for _, binding := range bindings {
+		r := &checkedRegistrar{server: server, binding: binding.name, registered: registered}
+		binding.register(r)
+		switch {
+		case r.err != nil:
+			errs = append(errs, r.err)
+		case r.count == 0:
+			errs = append(errs, fmt.Errorf("grpc service %s: registrar did not register a service", binding.name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkedRegistrar reports registration problems as errors instead of the
+// fatal log grpc.Server uses.
+type checkedRegistrar struct {
+	server     *grpc.Server
+	binding    string
+	registered map[string]string // Service name to binding name
+	count      int
+	err        error
+}
+
+// RegisterService implements grpc.ServiceRegistrar.
+func (r *checkedRegistrar) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	if r.err != nil {
+		return
+	}
+	if impl == nil || (reflect.ValueOf(impl).Kind() == reflect.Pointer && reflect.ValueOf(impl).IsNil()) {
+		r.err = fmt.Errorf("grpc service %s: implementation of %s is nil", r.binding, desc.ServiceName)
+		return
+	}
+	if desc.HandlerType != nil {
+		ht := reflect.TypeOf(desc.HandlerType).Elem()
+		if !reflect.TypeOf(impl).Implements(ht) {
+			r.err = fmt.Errorf("grpc service %s: %T does not implement %s", r.binding, impl, ht)
+			return
+		}
+	}
+	if other, ok := r.registered[desc.ServiceName]; ok {
+		r.err = fmt.Errorf("grpc service %s: %s is already registered by %s", r.binding, desc.ServiceName, other)
+		return
+	}
+
+	r.server.RegisterService(desc, impl)
+	r.registered[desc.ServiceName] = r.binding
+	r.count++
+}
+
+// funcName returns the name of a function for error messages.
+func funcName(fn any) string {
+	if f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()); f != nil {
+		return f.Name()
+	}
+	return fmt.Sprintf("%T", fn)
+}
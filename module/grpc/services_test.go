@@ -0,0 +1,159 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// fixedHealth is a health service implementation taking a dependency
+type fixedHealth struct {
+	healthpb.UnimplementedHealthServer
+	status healthpb.HealthCheckResponse_ServingStatus
+}
+
+func newFixedHealth(status healthpb.HealthCheckResponse_ServingStatus) healthpb.HealthServer {
+	return &fixedHealth{status: status}
+}
+
+// newHealthServer builds the standard health service from a params struct
+func newHealthServer(struct{ fx.In }) healthpb.HealthServer {
+	return health.NewServer()
+}
+
+func (h *fixedHealth) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	return &healthpb.HealthCheckResponse{Status: h.status}, nil
+}
+
+// newServiceApp returns an app serving on port with the built-in health service disabled
+func newServiceApp(port int, opts ...fx.Option) *fx.App {
+	v := viper.New()
+	v.Set("grpc.port", port)
+	v.Set("grpc.health.enabled", false)
+	return fx.New(append([]fx.Option{
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		grpcmodule.Module,
+	}, opts...)...)
+}
+
+// TestAsGrpcService verifies that services are constructed from the graph and served
+func TestAsGrpcService(t *testing.T) {
+	port := freePort(t)
+	app := newServiceApp(port,
+		fx.Supply(healthpb.HealthCheckResponse_SERVICE_UNKNOWN),
+		grpcmodule.AsGrpcService(newFixedHealth, healthpb.RegisterHealthServer),
+	)
+	require.NoError(t, app.Err())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, app.Start(ctx))
+	defer func() { _ = app.Stop(ctx) }()
+
+	conn, err := grpc.Dial("127.0.0.1:"+strconv.Itoa(port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVICE_UNKNOWN, resp.Status)
+}
+
+// TestAsGrpcServiceE verifies that services with fallible constructors are served
+func TestAsGrpcServiceE(t *testing.T) {
+	port := freePort(t)
+	app := newServiceApp(port,
+		grpcmodule.AsGrpcServiceE(func(struct{ fx.In }) (healthpb.HealthServer, error) {
+			return newFixedHealth(healthpb.HealthCheckResponse_SERVING), nil
+		}, healthpb.RegisterHealthServer),
+	)
+	require.NoError(t, app.Err())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, app.Start(ctx))
+	defer func() { _ = app.Stop(ctx) }()
+
+	conn, err := grpc.Dial("127.0.0.1:"+strconv.Itoa(port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+// TestAsGrpcServiceErrors verifies that bindings which cannot be registered fail startup
+func TestAsGrpcServiceErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []fx.Option
+		want    string
+	}{
+		{
+			name:    "nil implementation",
+			options: []fx.Option{grpcmodule.AsGrpcService(func(struct{ fx.In }) healthpb.HealthServer { return (*fixedHealth)(nil) }, healthpb.RegisterHealthServer)},
+			want:    "implementation of grpc.health.v1.Health is nil",
+		},
+		{
+			name: "duplicate service",
+			options: []fx.Option{
+				grpcmodule.AsGrpcService(newHealthServer, healthpb.RegisterHealthServer),
+				grpcmodule.AsGrpcService(newHealthServer, healthpb.RegisterHealthServer),
+			},
+			want: "grpc.health.v1.Health is already registered",
+		},
+		{
+			name: "registrar registers nothing",
+			options: []fx.Option{
+				grpcmodule.AsGrpcService(newHealthServer, func(grpc.ServiceRegistrar, healthpb.HealthServer) {}),
+			},
+			want: "registrar did not register a service",
+		},
+		{
+			name: "constructor fails",
+			options: []fx.Option{
+				grpcmodule.AsGrpcServiceE(func(struct{ fx.In }) (healthpb.HealthServer, error) {
+					return nil, errors.New("open user store")
+				}, healthpb.RegisterHealthServer),
+			},
+			want: "open user store",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newServiceApp(0, tt.options...).Err()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+// TestAsGrpcServiceConflictsWithHealth verifies that registering the built-in health service twice fails startup
+func TestAsGrpcServiceConflictsWithHealth(t *testing.T) {
+	err := fx.New(
+		fx.NopLogger,
+		fx.Supply(viper.New()),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		grpcmodule.Module,
+		grpcmodule.AsGrpcService(newHealthServer, healthpb.RegisterHealthServer),
+	).Err()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "grpc.health.v1.Health is already registered")
+}
@@ -0,0 +1,60 @@
+package grpc_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// TestShutdownHonorsDeadline verifies that a long-lived stream cannot block shutdown past the OnStop deadline
+func TestShutdownHonorsDeadline(t *testing.T) {
+	port := freePort(t)
+	v := viper.New()
+	v.Set("grpc.port", port)
+	v.Set("grpc.shutdown_delay", "50ms")
+
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		grpcmodule.Module,
+	)
+	app.RequireStart()
+
+	conn, err := grpc.Dial("127.0.0.1:"+strconv.Itoa(port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Watch streams stay open until the server closes them
+	stream, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_ = app.Stop(ctx) // Reports the expired deadline
+	elapsed := time.Since(start)
+
+	assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond, "shutdown_delay must be applied")
+	assert.Less(t, elapsed, time.Second, "shutdown must not wait for the stream")
+
+	// The stream reports NOT_SERVING, then is closed by the forced stop
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	assert.Error(t, err)
+}
@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/things-kit/module/tlsreload"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// TLSConfig holds the TLS settings of the gRPC server.
+type TLSConfig = tlsreload.Config
+
+// ClientIdentity is the identity of a client that presented a verified certificate.
+type ClientIdentity = tlsreload.ClientIdentity
+
+// ClientIdentityFromContext returns the identity of the client making the call.
+// It reports false unless the connection uses TLS and the client certificate
+// was verified against grpc.tls.client_ca_file.
+//
+// Example:
+//
+//	func (s *UserService) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+//	    id, ok := grpcmodule.ClientIdentityFromContext(ctx)
+//	    if !ok || id.SPIFFEID != "spiffe://example.org/ns/web/sa/frontend" {
+//	        return nil, status.Error(codes.PermissionDenied, "caller not allowed")
+//	    }
+//	    // ...
+//	}
+func ClientIdentityFromContext(ctx context.Context) (ClientIdentity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ClientIdentity{}, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ClientIdentity{}, false
+	}
+	return tlsreload.IdentityFromState(info.State)
+}
+
+// serverCredentials returns transport credentials that use the current files
+// of certs for each handshake.
+func serverCredentials(certs *tlsreload.Reloader) credentials.TransportCredentials {
+	return credentials.NewTLS(certs.TLSConfig("h2"))
+}
@@ -0,0 +1,224 @@
+// Package grpcgateway serves gRPC services as HTTP/JSON APIs on the httpgin engine.
+// Routes come from the google.api.http annotations compiled into the generated
+// grpc-gateway handlers; requests are forwarded to the application's gRPC server.
+package grpcgateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/spf13/viper"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"github.com/things-kit/module/grpc/client"
+	"github.com/things-kit/module/httpgin"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Module provides the gateway and mounts it on the httpgin engine.
+// It requires the grpc and httpgin modules.
+var Module = fx.Module("grpcgateway",
+	fx.Provide(
+		NewConfig,
+		NewGateway,
+	),
+	httpgin.AsGinHandler(func(g *Gateway) *Gateway { return g }),
+)
+
+// Config holds the gateway configuration.
+type Config struct {
+	Prefix          string   `mapstructure:"prefix"`           // Path prefix stripped before routing (empty = serve unmatched routes)
+	Endpoint        string   `mapstructure:"endpoint"`         // gRPC server address (default: the local server)
+	Client          string   `mapstructure:"client"`           // Name of a grpc.clients entry to use instead of endpoint
+	ForwardHeaders  []string `mapstructure:"forward_headers"`  // HTTP headers forwarded as gRPC metadata under their own name
+	UseProtoNames   bool     `mapstructure:"use_proto_names"`  // Use proto field names instead of lowerCamelCase in JSON
+	EmitUnpopulated bool     `mapstructure:"emit_unpopulated"` // Include zero-valued fields in JSON responses
+}
+
+// Registrar registers the routes of one service on the gateway. Generated
+// pb.RegisterXHandler functions have this signature.
+type Registrar func(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error
+
+// NewConfig creates the gateway configuration from Viper.
+func NewConfig(v *viper.Viper) *Config {
+	cfg := &Config{}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("grpc.gateway", cfg)
+	}
+
+	cfg.Prefix = strings.TrimSuffix(cfg.Prefix, "/")
+	if cfg.Prefix != "" && !strings.HasPrefix(cfg.Prefix, "/") {
+		cfg.Prefix = "/" + cfg.Prefix
+	}
+
+	return cfg
+}
+
+// GatewayParams contains the dependencies of NewGateway.
+type GatewayParams struct {
+	fx.In
+	Lifecycle   fx.Lifecycle
+	Logger      log.Logger
+	Config      *Config
+	Server      *grpcmodule.Config
+	Listener    net.Listener             `name:"grpc.listener" optional:"true"`
+	Connections *client.Connections      `optional:"true"`
+	Registrars  []Registrar              `group:"grpc.gateway.handlers"`
+	Options     []runtime.ServeMuxOption `group:"grpc.gateway.options"`
+}
+
+// Gateway translates HTTP/JSON requests into gRPC calls.
+type Gateway struct {
+	mux    *runtime.ServeMux
+	prefix string
+}
+
+// NewGateway connects to the gRPC server and registers every handler
+// contributed with AsGatewayHandler.
+func NewGateway(p GatewayParams) (*Gateway, error) {
+	conn, owned, err := dial(p)
+	if err != nil {
+		return nil, err
+	}
+	if owned {
+		p.Lifecycle.Append(fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				return conn.Close()
+			},
+		})
+	}
+
+	opts := []runtime.ServeMuxOption{
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   p.Config.UseProtoNames,
+				EmitUnpopulated: p.Config.EmitUnpopulated,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+		runtime.WithIncomingHeaderMatcher(headerMatcher(p.Config.ForwardHeaders)),
+	}
+	mux := runtime.NewServeMux(append(opts, p.Options...)...)
+
+	for _, register := range p.Registrars {
+		if err := register(context.Background(), mux, conn); err != nil {
+			return nil, fmt.Errorf("failed to register gateway handler: %w", err)
+		}
+	}
+
+	p.Logger.Info("Created gRPC gateway", log.Field{Key: "prefix", Value: p.Config.Prefix}, log.Field{Key: "handlers", Value: len(p.Registrars)})
+	return &Gateway{mux: mux, prefix: p.Config.Prefix}, nil
+}
+
+// dial returns the connection used by the gateway and whether the gateway owns it.
+func dial(p GatewayParams) (*grpc.ClientConn, bool, error) {
+	if p.Config.Client != "" {
+		if p.Connections == nil {
+			return nil, false, errors.New("grpc.gateway.client requires the grpc client module")
+		}
+		conn, err := p.Connections.Conn(p.Config.Client)
+		return conn, false, err
+	}
+
+	if p.Server.TLS.Enabled {
+		return nil, false, errors.New("grpc.tls is enabled: set grpc.gateway.client to a client configured with TLS")
+	}
+
+	endpoint := p.Config.Endpoint
+	if endpoint == "" {
+		host, port := p.Server.Host, strconv.Itoa(p.Server.Port)
+		if p.Listener != nil {
+			// The gRPC server shares a port (see module/sharedport)
+			host, port, _ = net.SplitHostPort(p.Listener.Addr().String())
+		}
+		if host == "" {
+			host = "localhost"
+		}
+		endpoint = net.JoinHostPort(host, port)
+	}
+
+	// Calls fail fast while the server is unreachable, so HTTP clients get a
+	// 503 instead of hanging until the server comes back
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create gRPC client for %s: %w", endpoint, err)
+	}
+	return conn, true, nil
+}
+
+// headerMatcher forwards the configured headers under their own name and
+// falls back to the grpc-gateway defaults for the rest.
+func headerMatcher(headers []string) runtime.HeaderMatcherFunc {
+	forward := make(map[string]bool, len(headers))
+	for _, h := range headers {
+		forward[http.CanonicalHeaderKey(h)] = true
+	}
+	return func(key string) (string, bool) {
+		if forward[http.CanonicalHeaderKey(key)] {
+			return strings.ToLower(key), true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	}
+}
+
+// RegisterRoutes implements httpgin.GinHandler. With a prefix, every path
+// under it is routed to the gateway; without one, the gateway serves the
+// requests no other route matches.
+func (g *Gateway) RegisterRoutes(engine *gin.Engine) {
+	if g.prefix == "" {
+		engine.NoRoute(gin.WrapH(g.mux))
+		return
+	}
+	engine.Any(g.prefix+"/*path", gin.WrapH(http.StripPrefix(g.prefix, g.mux)))
+}
+
+// ServeHTTP serves gateway requests without the prefix.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// AsGatewayHandler registers the HTTP/JSON routes of a service, usually the
+// generated pb.RegisterXHandler function. The service itself is registered
+// on the gRPC server with grpcmodule.AsGrpcService.
+//
+// Example:
+//
+//	grpcmodule.AsGrpcService(service.NewUserService, pb.RegisterUserServiceServer),
+//	grpcgateway.AsGatewayHandler(pb.RegisterUserServiceHandler),
+func AsGatewayHandler(registrar Registrar) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			func() Registrar { return registrar },
+			fx.ResultTags(`group:"grpc.gateway.handlers"`),
+		),
+	)
+}
+
+// AsServeMuxOption contributes a runtime.ServeMuxOption (error handlers,
+// metadata annotators, marshalers), applied after the built-in options.
+//
+// Example:
+//
+//	grpcgateway.AsServeMuxOption(func() runtime.ServeMuxOption {
+//	    return runtime.WithErrorHandler(myErrorHandler)
+//	})
+func AsServeMuxOption(constructor any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			constructor,
+			fx.ResultTags(`group:"grpc.gateway.options"`),
+		),
+	)
+}
@@ -0,0 +1,124 @@
+package grpcgateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"github.com/things-kit/module/grpcgateway"
+	"github.com/things-kit/module/httpgin"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+// freePort returns a TCP port that is currently free
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// registerHealthHandler stands in for a generated pb.RegisterXHandler function
+func registerHealthHandler(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error {
+	client := healthpb.NewHealthClient(conn)
+	return mux.HandlePath(http.MethodGet, "/v1/health", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		resp, err := client.Check(r.Context(), &healthpb.HealthCheckRequest{})
+		if err != nil {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": resp.Status.String()})
+	})
+}
+
+// TestGateway verifies that gateway routes are mounted under the prefix and reach the gRPC server
+func TestGateway(t *testing.T) {
+	grpcPort, httpPort := freePort(t), freePort(t)
+	v := viper.New()
+	v.Set("grpc.port", grpcPort)
+	v.Set("grpc.gateway.prefix", "api/")
+	v.Set("http.port", httpPort)
+
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		grpcmodule.Module,
+		httpgin.Module,
+		grpcgateway.Module,
+		grpcgateway.AsGatewayHandler(registerHealthHandler),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	base := "http://127.0.0.1:" + strconv.Itoa(httpPort)
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		var err error
+		resp, err = http.Get(base + "/api/v1/health")
+		return err == nil
+	}, 2*time.Second, 20*time.Millisecond)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "SERVING", body["status"])
+
+	notFound, err := http.Get(base + "/v1/health")
+	require.NoError(t, err)
+	notFound.Body.Close()
+	assert.Equal(t, http.StatusNotFound, notFound.StatusCode)
+}
+
+// TestGatewayServerDown verifies that requests fail fast with 503 while the gRPC server is unreachable
+func TestGatewayServerDown(t *testing.T) {
+	httpPort := freePort(t)
+	v := viper.New()
+	v.Set("grpc.port", freePort(t))
+	v.Set("grpc.gateway.endpoint", "127.0.0.1:"+strconv.Itoa(freePort(t)))
+	v.Set("http.port", httpPort)
+
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		grpcmodule.Module,
+		httpgin.Module,
+		grpcgateway.Module,
+		grpcgateway.AsGatewayHandler(registerHealthHandler),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get("http://127.0.0.1:" + strconv.Itoa(httpPort) + "/v1/health")
+	require.NoError(t, err, "requests must not wait for the server")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
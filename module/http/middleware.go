@@ -0,0 +1,102 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"go.uber.org/fx"
+)
+
+// Middleware wraps an HTTP handler. It is the middleware contract shared by
+// all implementations, so middleware written once runs on any of them.
+type Middleware func(next http.Handler) http.Handler
+
+// OrderedMiddleware is a Middleware contributed with AsMiddleware.
+type OrderedMiddleware struct {
+	Order      int
+	Middleware Middleware
+}
+
+var (
+	middlewareType = reflect.TypeOf(Middleware(nil))
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// AsMiddleware registers a middleware at the given order with the HTTP server,
+// whichever implementation provides it. Middleware run in ascending order, so
+// the first one is the outermost; middleware with equal order run in
+// registration order. The constructor may take any dependencies from the Fx
+// graph and must return a Middleware (or a func(http.Handler) http.Handler),
+// optionally with an error.
+//
+// Example:
+//
+//	httpmodule.AsMiddleware(func(logger log.Logger) httpmodule.Middleware {
+//	    return func(next http.Handler) http.Handler {
+//	        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	            w.Header().Set("X-Service", "orders")
+//	            next.ServeHTTP(w, r)
+//	        })
+//	    }
+//	}, 100)
+func AsMiddleware(constructor any, order int) fx.Option {
+	ctor := reflect.ValueOf(constructor)
+	ct := ctor.Type()
+	if ct.Kind() != reflect.Func || ct.NumOut() < 1 || ct.NumOut() > 2 ||
+		!ct.Out(0).ConvertibleTo(middlewareType) || (ct.NumOut() == 2 && ct.Out(1) != errorType) {
+		return fx.Error(fmt.Errorf("middleware constructor must be a func returning %s and optionally an error, got %T", middlewareType, constructor))
+	}
+
+	// Provide a function with the constructor's parameters returning the
+	// middleware with its order
+	in := make([]reflect.Type, ct.NumIn())
+	for i := range in {
+		in[i] = ct.In(i)
+	}
+	element := reflect.TypeOf(OrderedMiddleware{})
+	fn := reflect.MakeFunc(reflect.FuncOf(in, []reflect.Type{element, errorType}, ct.IsVariadic()),
+		func(args []reflect.Value) []reflect.Value {
+			var out []reflect.Value
+			if ct.IsVariadic() {
+				out = ctor.CallSlice(args)
+			} else {
+				out = ctor.Call(args)
+			}
+			if len(out) == 2 && !out[1].IsNil() {
+				return []reflect.Value{reflect.Zero(element), out[1]}
+			}
+			m := out[0].Convert(middlewareType).Interface().(Middleware)
+			return []reflect.Value{reflect.ValueOf(OrderedMiddleware{Order: order, Middleware: m}), reflect.Zero(errorType)}
+		})
+
+	return fx.Provide(
+		fx.Annotate(
+			fn.Interface(),
+			fx.ResultTags(`group:"http.middleware"`),
+		),
+	)
+}
+
+// SortMiddleware returns the middleware sorted by order, outermost first.
+func SortMiddleware(middleware []OrderedMiddleware) []Middleware {
+	sorted := make([]OrderedMiddleware, len(middleware))
+	copy(sorted, middleware)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Order < sorted[j].Order })
+
+	out := make([]Middleware, len(sorted))
+	for i, m := range sorted {
+		out[i] = m.Middleware
+	}
+	return out
+}
+
+// Chain wraps handler with the middleware sorted by order.
+func Chain(handler http.Handler, middleware []OrderedMiddleware) http.Handler {
+	sorted := SortMiddleware(middleware)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		handler = sorted[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,51 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	httpmodule "github.com/things-kit/module/http"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func tag(name string) func() httpmodule.Middleware {
+	return func() httpmodule.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Trace", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+}
+
+// TestAsMiddleware verifies that contributed middleware are chained by order
+func TestAsMiddleware(t *testing.T) {
+	var params struct {
+		fx.In
+		Middleware []httpmodule.OrderedMiddleware `group:"http.middleware"`
+	}
+	app := fxtest.New(t,
+		fx.NopLogger,
+		httpmodule.AsMiddleware(tag("inner"), 300),
+		httpmodule.AsMiddleware(tag("outer"), 100),
+		httpmodule.AsMiddleware(tag("middle"), 200),
+		fx.Populate(&params),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	handler := httpmodule.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), params.Middleware)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, []string{"outer", "middle", "inner"}, w.Header().Values("X-Trace"))
+}
+
+// TestAsMiddlewareInvalidConstructor verifies that invalid constructors fail the application
+func TestAsMiddlewareInvalidConstructor(t *testing.T) {
+	err := fx.New(fx.NopLogger, httpmodule.AsMiddleware(func() string { return "" }, 100)).Err()
+	assert.ErrorContains(t, err, "middleware constructor must be a func returning")
+}
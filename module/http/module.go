@@ -1,9 +1,11 @@
 // Package http defines framework-level HTTP server abstractions.
 // This package provides interfaces that HTTP implementations must satisfy,
 // allowing users to swap HTTP frameworks (Gin, Chi, Echo, stdlib, etc.) while
-// maintaining compatibility with the framework.
+// maintaining compatibility with the framework. Middleware contributed with
+// AsMiddleware run on every implementation.
 //
-// For a production-ready implementation, see the httpgin package.
+// Implementations are provided by the httpgin (Gin), httpstd (net/http
+// ServeMux) and httpchi (Chi) packages.
 package http
 
 import (
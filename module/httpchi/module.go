@@ -0,0 +1,224 @@
+// Package httpchi provides a Chi-based implementation of the Things-Kit HTTP
+// server interface. Chi routers are net/http compatible, so handlers and
+// middleware written for net/http run unchanged.
+package httpchi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+	httpmodule "github.com/things-kit/module/http"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+)
+
+// Module provides the Chi HTTP server module to the application.
+// This module implements the http.Server interface using Chi.
+var Module = fx.Module("httpchi",
+	fx.Provide(
+		NewConfig,
+		NewServer,
+		fx.Annotate(
+			func(s *Server) httpmodule.Server { return s },
+			fx.As(new(httpmodule.Server)),
+		),
+	),
+	fx.Invoke(RunHttpServer),
+)
+
+// Config holds the Chi HTTP server configuration.
+type Config struct {
+	httpmodule.Config `mapstructure:",squash"`
+}
+
+// ChiHandler is a Chi-specific implementation of http.Handler.
+// Handlers that implement this interface can be registered with AsChiHandler.
+type ChiHandler interface {
+	RegisterRoutes(router chi.Router)
+}
+
+// Server implements the http.Server interface using Chi.
+type Server struct {
+	router   *chi.Mux
+	handler  http.Handler // router wrapped with the middleware
+	server   *http.Server
+	listener net.Listener // Set on start, or replaces the listener on http.host/http.port
+	config   *Config
+	logger   log.Logger
+}
+
+// HttpServerParams contains all dependencies needed to run the HTTP server.
+type HttpServerParams struct {
+	fx.In
+	Lifecycle  fx.Lifecycle
+	Logger     log.Logger
+	Config     *Config
+	Handlers   []ChiHandler                   `group:"http.handlers"`
+	Middleware []httpmodule.OrderedMiddleware `group:"http.middleware"`
+
+	// Listener replaces the listener on http.host/http.port, e.g. to share a port with gRPC
+	Listener net.Listener `name:"http.listener" optional:"true"`
+}
+
+// NewConfig creates a new Chi HTTP configuration from Viper.
+func NewConfig(v *viper.Viper) *Config {
+	cfg := &Config{
+		Config: httpmodule.Config{
+			Port: 8080,
+			Host: "",
+		},
+	}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("http", cfg)
+	}
+
+	return cfg
+}
+
+// NewServer creates a new Chi server instance.
+func NewServer(config *Config, logger log.Logger) *Server {
+	router := chi.NewRouter()
+	return &Server{
+		router:  router,
+		handler: router,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// Start implements http.Server.Start
+func (s *Server) Start(ctx context.Context) error {
+	if s.listener == nil {
+		listener, err := net.Listen("tcp", s.Addr())
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", s.Addr(), err)
+		}
+		s.listener = listener
+	}
+
+	addr := s.Addr()
+	s.server = &http.Server{
+		Addr:    addr,
+		Handler: recoverer(s.handler, s.logger),
+	}
+
+	s.logger.Info("Starting Chi HTTP server", log.Field{Key: "address", Value: addr})
+
+	go func() {
+		if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Chi HTTP server error", err, log.Field{Key: "address", Value: addr})
+		}
+	}()
+
+	return nil
+}
+
+// Stop implements http.Server.Stop. In-flight requests are drained until ctx
+// expires, after which remaining connections are closed.
+func (s *Server) Stop(ctx context.Context) error {
+	s.logger.Info("Stopping Chi HTTP server", log.Field{Key: "address", Value: s.Addr()})
+
+	if s.server == nil {
+		return nil
+	}
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		_ = s.server.Close()
+		return err
+	}
+	return nil
+}
+
+// Addr implements http.Server.Addr
+func (s *Server) Addr() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return net.JoinHostPort(s.config.Host, fmt.Sprint(s.config.Port))
+}
+
+// Router returns the underlying Chi router.
+// This is useful for registering routes outside of handlers.
+func (s *Server) Router() chi.Router {
+	return s.router
+}
+
+// RunHttpServer starts the HTTP server with registered handlers, wrapping
+// the router with the middleware contributed with httpmodule.AsMiddleware.
+// This is invoked by Fx during application startup.
+func RunHttpServer(p HttpServerParams, server *Server) {
+	server.listener = p.Listener
+
+	// Register all provided handlers
+	for _, handler := range p.Handlers {
+		handler.RegisterRoutes(server.router)
+	}
+	server.handler = httpmodule.Chain(server.router, p.Middleware)
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return server.Start(ctx)
+		},
+		OnStop: func(ctx context.Context) error {
+			return server.Stop(ctx)
+		},
+	})
+}
+
+// AsChiHandler is a generic helper to provide a Chi HTTP handler to the Fx graph.
+// The constructor should return a type that implements the ChiHandler interface.
+//
+// Example:
+//
+//	type UserHandler struct {
+//	    store *UserStore
+//	}
+//
+//	func (h *UserHandler) RegisterRoutes(r chi.Router) {
+//	    r.Route("/users", func(r chi.Router) {
+//	        r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+//	            user, err := h.store.Get(r.Context(), chi.URLParam(r, "id"))
+//	            ...
+//	        })
+//	    })
+//	}
+//
+//	// In main.go:
+//	httpchi.AsChiHandler(NewUserHandler)
+func AsChiHandler(constructor any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			constructor,
+			fx.As(new(ChiHandler)),
+			fx.ResultTags(`group:"http.handlers"`),
+		),
+	)
+}
+
+// recoverer converts handler panics into 500 responses, logging the panic and stack.
+func recoverer(next http.Handler, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if p := recover(); p != nil {
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				logger.ErrorC(r.Context(), "Panic in HTTP handler", fmt.Errorf("panic: %v", p),
+					log.Field{Key: "method", Value: r.Method},
+					log.Field{Key: "path", Value: r.URL.Path},
+					log.Field{Key: "stack", Value: string(debug.Stack())},
+				)
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
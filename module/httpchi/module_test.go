@@ -0,0 +1,90 @@
+package httpchi_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	httpmodule "github.com/things-kit/module/http"
+	"github.com/things-kit/module/httpchi"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+type userHandler struct{}
+
+func (userHandler) RegisterRoutes(router chi.Router) {
+	router.Route("/users", func(r chi.Router) {
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "user "+chi.URLParam(r, "id"))
+		})
+	})
+	router.Get("/panic", func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+}
+
+// TestServer verifies routing, shared middleware and panic recovery
+func TestServer(t *testing.T) {
+	v := viper.New()
+	v.Set("http.host", "127.0.0.1")
+	v.Set("http.port", 0)
+
+	var server httpmodule.Server
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		httpchi.Module,
+		httpchi.AsChiHandler(func() userHandler { return userHandler{} }),
+		httpmodule.AsMiddleware(func() httpmodule.Middleware {
+			return func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("X-Middleware", "shared")
+					next.ServeHTTP(w, r)
+				})
+			}
+		}, 100),
+		fx.Populate(&server),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get("http://" + server.Addr() + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	resp, body := get("/users/42")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "user 42", body)
+	assert.Equal(t, "shared", resp.Header.Get("X-Middleware"))
+
+	resp, _ = get("/panic")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	resp, _ = get("/missing")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,126 @@
+package httpgin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/things-kit/module/httpgin"
+)
+
+// TestCORS verifies preflight and simple requests from allowed and other origins
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(httpgin.CORS(httpgin.CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com", "https://*.preview.example.com"},
+		AllowMethods:     []string{"GET", "POST"},
+		AllowHeaders:     []string{"Content-Type", "Authorization"},
+		ExposeHeaders:    []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}))
+	engine.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name      string
+		method    string
+		origin    string
+		preflight bool
+		code      int
+		headers   map[string]string
+	}{
+		{
+			name:   "same origin",
+			method: http.MethodGet,
+			code:   http.StatusOK,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:   "allowed origin",
+			method: http.MethodGet, origin: "https://app.example.com",
+			code: http.StatusOK,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "X-Request-ID",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name:   "wildcard subdomain",
+			method: http.MethodGet, origin: "https://pr-42.preview.example.com",
+			code: http.StatusOK,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin": "https://pr-42.preview.example.com",
+			},
+		},
+		{
+			name:   "other origin",
+			method: http.MethodGet, origin: "https://evil.example.net",
+			code: http.StatusOK,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:   "preflight",
+			method: http.MethodOptions, origin: "https://app.example.com", preflight: true,
+			code: http.StatusNoContent,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Content-Type, Authorization",
+				"Access-Control-Max-Age":       "3600",
+			},
+		},
+		{
+			name:   "preflight from other origin",
+			method: http.MethodOptions, origin: "https://preview.example.com", preflight: true,
+			code: http.StatusForbidden,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/orders", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+			for name, value := range tt.headers {
+				assert.Equal(t, value, w.Header().Get(name), name)
+			}
+		})
+	}
+}
+
+// TestCORSAnyOrigin verifies that "*" is answered with "*", never by reflecting the origin
+func TestCORSAnyOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, credentials := range []bool{false, true} {
+		engine := gin.New()
+		engine.Use(httpgin.CORS(httpgin.CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: credentials}))
+		engine.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set("Origin", "https://evil.example.net")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
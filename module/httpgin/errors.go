@@ -0,0 +1,25 @@
+package httpgin
+
+import (
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// WriteStatus writes st with the given HTTP status code and aborts the chain.
+// The body is the JSON form of google.rpc.Status, the same format
+// grpc-gateway uses:
+//
+//	{"code": 5, "message": "user not found", "details": [{"@type": "type.googleapis.com/google.rpc.ErrorInfo", ...}]}
+//
+// The built-in middleware answer errors in this format, and so do the
+// middleware contributed by the errmap, auth, authz and validation modules.
+func WriteStatus(c *gin.Context, code int, st *status.Status) {
+	body, err := protojson.Marshal(st.Proto())
+	if err != nil {
+		c.AbortWithStatus(code)
+		return
+	}
+	c.Data(code, "application/json", body)
+	c.Abort()
+}
@@ -0,0 +1,175 @@
+package httpgin
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GzipConfig holds the settings of the Gzip middleware.
+type GzipConfig struct {
+	Enabled   bool `mapstructure:"enabled"`
+	Level     int  `mapstructure:"level"`      // compress/gzip level, from -2 (Huffman only) to 9 (-1 = default)
+	MinLength int  `mapstructure:"min_length"` // Responses shorter than this are sent uncompressed
+}
+
+// Gzip compresses responses for clients that accept gzip. Responses shorter
+// than cfg.MinLength, without a body, or already encoded by the handler are
+// sent as they are. It is installed at OrderGzip with
+// http.middleware.gzip.enabled; cfg.Level must be a valid compress/gzip level.
+func Gzip(cfg GzipConfig) gin.HandlerFunc {
+	pool := &sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, cfg.Level)
+		return w
+	}}
+
+	return WrapMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r.Header.Get("Accept-Encoding")) || r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipWriter{ResponseWriter: w, pool: pool, minLength: cfg.MinLength}
+			defer gw.close()
+			next.ServeHTTP(gw, r)
+		})
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header accepts gzip.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		q, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !found {
+			return true
+		}
+		if v, err := strconv.ParseFloat(q, 64); err == nil && v > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipWriter buffers the start of a response until it reaches minLength, then
+// decides whether to compress it.
+type gzipWriter struct {
+	http.ResponseWriter
+	pool      *sync.Pool
+	minLength int
+
+	status  int
+	buf     []byte
+	started bool
+	gz      *gzip.Writer
+}
+
+// WriteHeader records the status, which is sent once the encoding is decided.
+func (w *gzipWriter) WriteHeader(code int) {
+	if !w.started && w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write buffers data until minLength is reached.
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if !w.started {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.minLength {
+			return len(data), nil
+		}
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush sends the buffered data, compressed when the response can be.
+func (w *gzipWriter) Flush() {
+	if !w.started {
+		_ = w.start(true)
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start writes the header and the buffered data, compressing them if
+// compress is set and the response allows it.
+func (w *gzipWriter) start(compress bool) error {
+	w.started = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	h := w.Header()
+	if compress && h.Get("Content-Encoding") == "" && h.Get("Content-Range") == "" && bodyAllowed(w.status) {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+			h.Set("Content-Type", http.DetectContentType(w.buf))
+		}
+		w.gz = w.pool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close sends what was not sent yet and finishes the compressed stream.
+func (w *gzipWriter) close() {
+	if !w.started {
+		if w.status == 0 && len(w.buf) == 0 {
+			return // Nothing was written, e.g. after a panic
+		}
+		_ = w.start(false)
+	}
+	if w.gz != nil {
+		_ = w.gz.Close()
+		w.gz.Reset(io.Discard)
+		w.pool.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+// bodyAllowed reports whether a response with status can have a compressible body.
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent &&
+		status != http.StatusPartialContent && status != http.StatusNotModified
+}
@@ -0,0 +1,72 @@
+package httpgin_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/httpgin"
+)
+
+// TestGzip verifies which responses are compressed
+func TestGzip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	large := strings.Repeat("things-kit ", 100)
+
+	engine := gin.New()
+	engine.Use(httpgin.Gzip(httpgin.GzipConfig{Level: gzip.DefaultCompression, MinLength: 256}))
+	engine.GET("/large", func(c *gin.Context) { c.String(http.StatusOK, large) })
+	engine.GET("/small", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	engine.GET("/empty", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	engine.GET("/encoded", func(c *gin.Context) {
+		c.Header("Content-Encoding", "br")
+		c.String(http.StatusOK, large)
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		code           int
+		compressed     bool
+		body           string
+	}{
+		{"large", "/large", "gzip, deflate", http.StatusOK, true, large},
+		{"not accepted", "/large", "deflate", http.StatusOK, false, large},
+		{"refused", "/large", "gzip;q=0", http.StatusOK, false, large},
+		{"small", "/small", "gzip", http.StatusOK, false, "ok"},
+		{"no body", "/empty", "gzip", http.StatusNoContent, false, ""},
+		{"already encoded", "/encoded", "gzip", http.StatusOK, false, large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			body := w.Body.String()
+			if tt.compressed {
+				assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+				assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"))
+				r, err := gzip.NewReader(w.Body)
+				require.NoError(t, err)
+				b, err := io.ReadAll(r)
+				require.NoError(t, err)
+				body = string(b)
+			} else {
+				assert.NotEqual(t, "gzip", w.Header().Get("Content-Encoding"))
+			}
+			assert.Equal(t, tt.body, body)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package httpgin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/things-kit/module/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AccessLogConfig holds the settings of the AccessLog middleware.
+type AccessLogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// SkipRoutes are not logged, e.g. "/healthz" or "GET /metrics". Entries
+	// match the route pattern, optionally prefixed with the method, or every
+	// route under a prefix when they end with "*".
+	SkipRoutes []string `mapstructure:"skip_routes"`
+}
+
+// Recovery converts panics in handlers into 500 responses in the
+// google.rpc.Status format of WriteStatus, and logs them with their stack
+// trace and the request context. Panic values are never sent to clients.
+// It is installed at OrderRecovery unless http.middleware.recovery is false.
+func Recovery(logger log.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				panic(r)
+			}
+
+			logger.ErrorC(c.Request.Context(), "HTTP handler panic", fmt.Errorf("panic: %v", r),
+				log.Field{Key: "method", Value: c.Request.Method},
+				log.Field{Key: "route", Value: c.FullPath()},
+				log.Field{Key: "stack", Value: string(debug.Stack())},
+			)
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			WriteStatus(c, http.StatusInternalServerError, status.New(codes.Internal, "internal error"))
+		}()
+		c.Next()
+	}
+}
+
+// AccessLog logs every request with its method, route, path, status,
+// duration, response size, client IP and user agent, through logger and with
+// the request context. Requests answered with a 5xx are logged as errors and
+// those answered with a 4xx as warnings, with the last error attached with
+// c.Error. Routes matching skip are not logged. It is installed at
+// OrderAccessLog with http.middleware.access_log.enabled.
+func AccessLog(logger log.Logger, skip []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if matchRoute(skip, c.Request.Method, route) {
+			return
+		}
+
+		status := c.Writer.Status()
+		fields := []log.Field{
+			{Key: "method", Value: c.Request.Method},
+			{Key: "route", Value: route},
+			{Key: "path", Value: c.Request.URL.Path},
+			{Key: "status", Value: status},
+			{Key: "duration", Value: time.Since(start)},
+			{Key: "size", Value: max(c.Writer.Size(), 0)},
+			{Key: "client_ip", Value: c.ClientIP()},
+			{Key: "user_agent", Value: c.Request.UserAgent()},
+		}
+
+		var err error
+		if last := c.Errors.Last(); last != nil {
+			err = last.Err
+		}
+		ctx := c.Request.Context()
+		switch {
+		case status >= http.StatusInternalServerError:
+			if err == nil {
+				err = errors.New(http.StatusText(status))
+			}
+			logger.ErrorC(ctx, "HTTP request failed", err, fields...)
+		case status >= http.StatusBadRequest:
+			logger.WarnC(ctx, "HTTP request failed", err, fields...)
+		default:
+			logger.InfoC(ctx, "HTTP request", fields...)
+		}
+	}
+}
+
+// matchRoute reports whether an entry of patterns matches route, by itself or
+// prefixed with method as in "GET /metrics". Entries ending with "*" match by
+// prefix.
+func matchRoute(patterns []string, method, route string) bool {
+	for _, pattern := range patterns {
+		prefix, wildcard := strings.CutSuffix(pattern, "*")
+		for _, name := range []string{route, method + " " + route} {
+			if name == pattern || (wildcard && strings.HasPrefix(name, prefix)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,285 @@
+package httpgin
+
+import (
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	httpmodule "github.com/things-kit/module/http"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+)
+
+// Orders of the built-in middleware. Middleware run in ascending order, so
+// the first one is the outermost; middleware with equal order run in
+// registration order. Middleware contributed with AsGinMiddleware and
+// httpmodule.AsMiddleware are ordered among them; OrderErrors to
+// OrderValidation are the orders of the middleware contributed by the errmap,
+// auth, authz and validation modules.
+const (
+	OrderRequestID       = 100
+	OrderRealIP          = 150
+	OrderAccessLog       = 200
+	OrderRecovery        = 250
+	OrderSecurityHeaders = 300
+	OrderCORS            = 350
+	OrderGzip            = 400
+	OrderBodyLimit       = 450
+	OrderErrors          = 500
+	OrderAuth            = 550
+	OrderAuthorization   = 575
+	OrderValidation      = 600
+
+	// OrderDefault is a suggested order for application middleware,
+	// running inside all built-in middleware.
+	OrderDefault = 1000
+)
+
+// MiddlewareConfig holds the settings of the built-in middleware.
+type MiddlewareConfig struct {
+	Recovery        bool                  `mapstructure:"recovery"` // Convert handler panics into 500 responses
+	RequestID       RequestIDConfig       `mapstructure:"request_id"`
+	RealIP          RealIPConfig          `mapstructure:"real_ip"`
+	AccessLog       AccessLogConfig       `mapstructure:"access_log"`
+	SecurityHeaders SecurityHeadersConfig `mapstructure:"security_headers"`
+	CORS            CORSConfig            `mapstructure:"cors"`
+	Gzip            GzipConfig            `mapstructure:"gzip"`
+	BodyLimit       BodyLimitConfig       `mapstructure:"body_limit"`
+}
+
+// defaultMiddlewareConfig returns the defaults of http.middleware. Only
+// recovery is enabled; the other middleware use these settings once enabled.
+func defaultMiddlewareConfig() MiddlewareConfig {
+	return MiddlewareConfig{
+		Recovery:  true,
+		RequestID: RequestIDConfig{Header: "X-Request-ID"},
+		RealIP:    RealIPConfig{Headers: []string{"X-Forwarded-For", "X-Real-IP"}},
+		SecurityHeaders: SecurityHeadersConfig{
+			ContentTypeOptions: "nosniff",
+			FrameOptions:       "DENY",
+			ReferrerPolicy:     "strict-origin-when-cross-origin",
+		},
+		CORS: CORSConfig{
+			AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
+			AllowHeaders: []string{"Origin", "Accept", "Content-Type", "Authorization", "X-Request-ID"},
+			MaxAge:       12 * time.Hour,
+		},
+		Gzip: GzipConfig{
+			Level:     gzip.DefaultCompression,
+			MinLength: 1024,
+		},
+		BodyLimit: BodyLimitConfig{MaxBytes: 4 << 20},
+	}
+}
+
+// GinMiddleware is an ordered Gin middleware contributed with AsGinMiddleware.
+type GinMiddleware struct {
+	Order   int
+	Handler gin.HandlerFunc
+}
+
+var (
+	handlerFuncType = reflect.TypeOf(gin.HandlerFunc(nil))
+	errorType       = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// AsGinMiddleware registers a Gin middleware at the given order. The
+// constructor may take any dependencies from the Fx graph and must return a
+// gin.HandlerFunc, optionally with an error. Middleware that should also run
+// on other HTTP implementations are better registered with
+// httpmodule.AsMiddleware.
+//
+// Example:
+//
+//	httpgin.AsGinMiddleware(func(limiter ratelimit.Limiter, logger log.Logger) (gin.HandlerFunc, error) {
+//	    return ratelimitgin.RateLimit(limiter, logger, "api", ratelimit.PerSecond(50), ratelimitgin.ByIP())
+//	}, httpgin.OrderDefault)
+func AsGinMiddleware(constructor any, order int) fx.Option {
+	ctor := reflect.ValueOf(constructor)
+	ct := ctor.Type()
+	if ct.Kind() != reflect.Func || ct.NumOut() < 1 || ct.NumOut() > 2 ||
+		!ct.Out(0).ConvertibleTo(handlerFuncType) || (ct.NumOut() == 2 && ct.Out(1) != errorType) {
+		return fx.Error(fmt.Errorf("middleware constructor must be a func returning %s and optionally an error, got %T", handlerFuncType, constructor))
+	}
+
+	// Provide a function with the constructor's parameters returning the
+	// middleware with its order
+	in := make([]reflect.Type, ct.NumIn())
+	for i := range in {
+		in[i] = ct.In(i)
+	}
+	element := reflect.TypeOf(GinMiddleware{})
+	fn := reflect.MakeFunc(reflect.FuncOf(in, []reflect.Type{element, errorType}, ct.IsVariadic()),
+		func(args []reflect.Value) []reflect.Value {
+			var out []reflect.Value
+			if ct.IsVariadic() {
+				out = ctor.CallSlice(args)
+			} else {
+				out = ctor.Call(args)
+			}
+			if len(out) == 2 && !out[1].IsNil() {
+				return []reflect.Value{reflect.Zero(element), out[1]}
+			}
+			h := out[0].Convert(handlerFuncType).Interface().(gin.HandlerFunc)
+			return []reflect.Value{reflect.ValueOf(GinMiddleware{Order: order, Handler: h}), reflect.Zero(errorType)}
+		})
+
+	return fx.Provide(
+		fx.Annotate(
+			fn.Interface(),
+			fx.ResultTags(`group:"httpgin.middleware"`),
+		),
+	)
+}
+
+// buildMiddleware returns the built-in middleware enabled in cfg and the
+// contributed ones, sorted by order.
+func buildMiddleware(cfg MiddlewareConfig, logger log.Logger, contributed []GinMiddleware, shared []httpmodule.OrderedMiddleware) ([]gin.HandlerFunc, error) {
+	var builtin []GinMiddleware
+	if cfg.RequestID.Enabled {
+		builtin = append(builtin, GinMiddleware{Order: OrderRequestID, Handler: RequestID(cfg.RequestID.Header)})
+	}
+	if cfg.RealIP.Enabled {
+		builtin = append(builtin, GinMiddleware{Order: OrderRealIP, Handler: RealIP()})
+	}
+	if cfg.AccessLog.Enabled {
+		builtin = append(builtin, GinMiddleware{Order: OrderAccessLog, Handler: AccessLog(logger, cfg.AccessLog.SkipRoutes)})
+	}
+	if cfg.Recovery {
+		builtin = append(builtin, GinMiddleware{Order: OrderRecovery, Handler: Recovery(logger)})
+	}
+	if cfg.SecurityHeaders.Enabled {
+		builtin = append(builtin, GinMiddleware{Order: OrderSecurityHeaders, Handler: SecurityHeaders(cfg.SecurityHeaders)})
+	}
+	if cfg.CORS.Enabled {
+		if len(cfg.CORS.AllowOrigins) == 0 {
+			return nil, fmt.Errorf("http.middleware.cors requires allow_origins")
+		}
+		if cfg.CORS.AllowCredentials && slices.Contains(cfg.CORS.AllowOrigins, "*") {
+			return nil, fmt.Errorf("http.middleware.cors cannot allow credentials from any origin (\"*\")")
+		}
+		builtin = append(builtin, GinMiddleware{Order: OrderCORS, Handler: CORS(cfg.CORS)})
+	}
+	if cfg.Gzip.Enabled {
+		if cfg.Gzip.Level < gzip.HuffmanOnly || cfg.Gzip.Level > gzip.BestCompression {
+			return nil, fmt.Errorf("invalid http.middleware.gzip.level %d", cfg.Gzip.Level)
+		}
+		builtin = append(builtin, GinMiddleware{Order: OrderGzip, Handler: Gzip(cfg.Gzip)})
+	}
+	if cfg.BodyLimit.Enabled {
+		if cfg.BodyLimit.MaxBytes <= 0 {
+			return nil, fmt.Errorf("http.middleware.body_limit requires a positive max_bytes")
+		}
+		builtin = append(builtin, GinMiddleware{Order: OrderBodyLimit, Handler: BodyLimit(cfg.BodyLimit.MaxBytes)})
+	}
+
+	middleware := append(builtin, contributed...)
+	for _, m := range shared {
+		middleware = append(middleware, GinMiddleware{Order: m.Order, Handler: WrapMiddleware(m.Middleware)})
+	}
+	sort.SliceStable(middleware, func(i, j int) bool { return middleware[i].Order < middleware[j].Order })
+
+	handlers := make([]gin.HandlerFunc, len(middleware))
+	for i, m := range middleware {
+		handlers[i] = m.Handler
+	}
+	return handlers, nil
+}
+
+// WrapMiddleware adapts a net/http middleware to Gin, so middleware written
+// against the shared httpmodule.Middleware contract run on Gin too. Changes
+// the middleware makes to the request (e.g. its context) and to the response
+// writer (e.g. compression) are seen by the next handlers; a middleware that
+// does not call the next handler aborts the chain. Middleware contributed
+// with httpmodule.AsMiddleware are installed automatically, by order.
+//
+// The request is routed before middleware run, so rewriting its path does not
+// change the route.
+func WrapMiddleware(m httpmodule.Middleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := c.Writer
+		called := false
+
+		m(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			c.Request = r
+			if w == writer {
+				c.Next()
+				return
+			}
+
+			// Restore the writer even if a handler panics, so that outer
+			// middleware such as Recovery write to the original one
+			mw := &middlewareWriter{ResponseWriter: writer, w: w, status: http.StatusOK, size: -1}
+			c.Writer = mw
+			defer func() { c.Writer = writer }()
+			c.Next()
+			mw.WriteHeaderNow()
+		})).ServeHTTP(writer, c.Request)
+
+		if !called {
+			c.Abort()
+		}
+	}
+}
+
+// middlewareWriter sends the response of the next handlers to the writer a
+// net/http middleware passed on, keeping Gin's view of the response consistent.
+type middlewareWriter struct {
+	gin.ResponseWriter
+	w      http.ResponseWriter
+	status int
+	size   int
+}
+
+func (w *middlewareWriter) Header() http.Header {
+	return w.w.Header()
+}
+
+func (w *middlewareWriter) WriteHeader(code int) {
+	if code > 0 && !w.Written() {
+		w.status = code
+	}
+}
+
+func (w *middlewareWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+		w.w.WriteHeader(w.status)
+	}
+}
+
+func (w *middlewareWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.w.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *middlewareWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *middlewareWriter) Status() int {
+	return w.status
+}
+
+func (w *middlewareWriter) Size() int {
+	return w.size
+}
+
+func (w *middlewareWriter) Written() bool {
+	return w.size != -1
+}
+
+func (w *middlewareWriter) Flush() {
+	w.WriteHeaderNow()
+	if f, ok := w.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
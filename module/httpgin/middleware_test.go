@@ -0,0 +1,273 @@
+package httpgin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/httpgin"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+// upperWriter upper-cases the response body
+type upperWriter struct {
+	http.ResponseWriter
+}
+
+func (w upperWriter) Write(b []byte) (int, error) {
+	return w.ResponseWriter.Write(bytes.ToUpper(b))
+}
+
+// TestWrapMiddleware verifies request, response writer and short-circuit handling of net/http middleware
+func TestWrapMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(httpgin.WrapMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Block") != "" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			r.Header.Set("X-Seen", "yes")
+			next.ServeHTTP(upperWriter{w}, r)
+		})
+	}))
+
+	handled := false
+	engine.GET("/hello", func(c *gin.Context) {
+		handled = true
+		c.String(http.StatusCreated, "hello %s", c.GetHeader("X-Seen"))
+		assert.Equal(t, http.StatusCreated, c.Writer.Status())
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "HELLO YES", w.Body.String())
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"))
+
+	handled = false
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+	req.Header.Set("X-Block", "1")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.False(t, handled)
+}
+
+// entry is a log entry recorded by recordingLogger
+type entry struct {
+	msg    string
+	fields map[string]any
+}
+
+// recordingLogger records the entries logged with a context, including the context's fields
+type recordingLogger struct {
+	nopLogger
+	mu      sync.Mutex
+	entries []entry
+}
+
+func (l *recordingLogger) record(ctx context.Context, msg string, fields []log.Field) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := entry{msg: msg, fields: map[string]any{}}
+	for _, f := range append(fields, log.FieldsFrom(ctx)...) {
+		e.fields[f.Key] = f.Value
+	}
+	l.entries = append(l.entries, e)
+}
+
+func (l *recordingLogger) InfoC(ctx context.Context, msg string, fields ...log.Field) {
+	l.record(ctx, msg, fields)
+}
+
+func (l *recordingLogger) ErrorC(ctx context.Context, msg string, _ error, fields ...log.Field) {
+	l.record(ctx, msg, fields)
+}
+
+func (l *recordingLogger) WarnC(ctx context.Context, msg string, _ error, fields ...log.Field) {
+	l.record(ctx, msg, fields)
+}
+
+func (l *recordingLogger) last() entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.entries[len(l.entries)-1]
+}
+
+type middlewareHandler struct{}
+
+func (middlewareHandler) RegisterRoutes(engine *gin.Engine) {
+	engine.GET("/echo", func(c *gin.Context) {
+		id, _ := httpgin.RequestIDFrom(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"request_id": id, "remote_addr": c.Request.RemoteAddr, "order": c.GetStringSlice("order")})
+	})
+	engine.POST("/upload", func(c *gin.Context) {
+		var tooLarge *http.MaxBytesError
+		if _, err := io.ReadAll(c.Request.Body); errors.As(err, &tooLarge) {
+			c.String(http.StatusRequestEntityTooLarge, "cut off at %d bytes", tooLarge.Limit)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	engine.GET("/panic", func(*gin.Context) {
+		panic("boom")
+	})
+}
+
+// TestBuiltinMiddleware verifies the middleware enabled under http.middleware and their order
+func TestBuiltinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	v := viper.New()
+	v.Set("http.port", 0)
+	v.Set("http.middleware.request_id.enabled", true)
+	v.Set("http.middleware.access_log.enabled", true)
+	v.Set("http.middleware.access_log.skip_routes", []string{"GET /healthz"})
+	v.Set("http.middleware.real_ip.enabled", true)
+	v.Set("http.middleware.real_ip.trusted_proxies", []string{"10.0.0.0/8"})
+	v.Set("http.middleware.security_headers.enabled", true)
+	v.Set("http.middleware.body_limit.enabled", true)
+	v.Set("http.middleware.body_limit.max_bytes", 16)
+
+	order := func(name string) func() gin.HandlerFunc {
+		return func() gin.HandlerFunc {
+			return func(c *gin.Context) {
+				_, hasID := httpgin.RequestIDFrom(c.Request.Context())
+				c.Set("order", append(c.GetStringSlice("order"), fmt.Sprintf("%s:%t", name, hasID)))
+				c.Next()
+			}
+		}
+	}
+
+	logger := &recordingLogger{}
+	var server *httpgin.GinServer
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return logger }),
+		httpgin.Module,
+		httpgin.AsGinHandler(func() middlewareHandler { return middlewareHandler{} }),
+		httpgin.AsGinMiddleware(order("app"), httpgin.OrderDefault),
+		httpgin.AsGinMiddleware(order("first"), 50),
+		fx.Populate(&server),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.Engine().ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("request ID and real IP", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/echo", nil)
+		req.RemoteAddr = "10.1.2.3:4567"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		req.Header.Set("X-Request-ID", "req-1")
+		w := serve(req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "req-1", w.Header().Get("X-Request-ID"))
+		assert.JSONEq(t, `{"request_id": "req-1", "remote_addr": "203.0.113.7:4567", "order": ["first:false", "app:true"]}`, w.Body.String())
+		assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+
+		logged := logger.last()
+		assert.Equal(t, "HTTP request", logged.msg)
+		assert.Equal(t, "req-1", logged.fields["request_id"])
+		assert.Equal(t, "/echo", logged.fields["route"])
+		assert.Equal(t, http.StatusOK, logged.fields["status"])
+		assert.Equal(t, "203.0.113.7", logged.fields["client_ip"])
+	})
+
+	t.Run("untrusted proxy", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/echo", nil)
+		req.RemoteAddr = "192.0.2.1:4567"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		req.Header.Set("X-Request-ID", "not valid")
+		w := serve(req)
+
+		var body struct {
+			RequestID  string `json:"request_id"`
+			RemoteAddr string `json:"remote_addr"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "192.0.2.1:4567", body.RemoteAddr)
+		assert.Len(t, body.RequestID, 36)
+		assert.Equal(t, body.RequestID, w.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("recovery", func(t *testing.T) {
+		w := serve(httptest.NewRequest(http.MethodGet, "/panic", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.JSONEq(t, `{"code": 13, "message": "internal error"}`, w.Body.String())
+		assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+
+		logged := logger.last()
+		assert.Equal(t, "HTTP request failed", logged.msg)
+		assert.Equal(t, http.StatusInternalServerError, logged.fields["status"])
+	})
+
+	t.Run("body limit", func(t *testing.T) {
+		w := serve(httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(`{"a": "b"}`)))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = serve(httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(`{"name": "too large"}`)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.JSONEq(t, `{"code": 3, "message": "request body exceeds 16 bytes"}`, w.Body.String())
+
+		// Bodies of unknown length are cut off while reading
+		req := httptest.NewRequest(http.MethodPost, "/upload", io.MultiReader(strings.NewReader(`{"name": "too large"}`)))
+		req.ContentLength = -1
+		w = serve(req)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Equal(t, "cut off at 16 bytes", w.Body.String())
+	})
+}
+
+// TestMiddlewareConfigErrors verifies that invalid middleware settings fail the application start
+func TestMiddlewareConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]any
+	}{
+		{"cors without origins", map[string]any{"cors.enabled": true}},
+		{"cors credentials from any origin", map[string]any{"cors.enabled": true, "cors.allow_origins": []string{"*"}, "cors.allow_credentials": true}},
+		{"invalid gzip level", map[string]any{"gzip.enabled": true, "gzip.level": 12}},
+		{"real ip without proxies", map[string]any{"real_ip.enabled": true}},
+		{"invalid proxy", map[string]any{"real_ip.enabled": true, "real_ip.trusted_proxies": []string{"not-an-ip"}}},
+		{"zero body limit", map[string]any{"body_limit.enabled": true, "body_limit.max_bytes": 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := viper.New()
+			for key, value := range tt.config {
+				v.Set("http.middleware."+key, value)
+			}
+			err := fx.New(
+				fx.NopLogger,
+				fx.Supply(v),
+				fx.Provide(func() log.Logger { return nopLogger{} }),
+				httpgin.Module,
+			).Err()
+			assert.Error(t, err)
+		})
+	}
+}
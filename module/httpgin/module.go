@@ -5,7 +5,10 @@ package httpgin
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -13,7 +16,10 @@ import (
 	"github.com/spf13/viper"
 	httpmodule "github.com/things-kit/module/http"
 	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/tlsreload"
 	"go.uber.org/fx"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 // Module provides the Gin-based HTTP server module to the application.
@@ -35,6 +41,17 @@ var Module = fx.Module("httpgin",
 type Config struct {
 	httpmodule.Config `mapstructure:",squash"`
 	Mode              string `mapstructure:"mode"` // debug, release, test
+
+	// Timeouts and limits; zero values keep the net/http defaults (no timeouts, 1MB of headers)
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`        // Reading the whole request, including the body
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"` // Reading the request headers
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`       // From the end of the request headers to the end of the response
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`        // Keep-alive connections waiting for the next request
+	MaxHeaderBytes    int           `mapstructure:"max_header_bytes"`
+
+	H2C        bool             `mapstructure:"h2c"` // Serve HTTP/2 without TLS, e.g. behind a proxy speaking HTTP/2 to backends
+	TLS        TLSConfig        `mapstructure:"tls"`
+	Middleware MiddlewareConfig `mapstructure:"middleware"`
 }
 
 // GinHandler is a Gin-specific implementation of http.Handler.
@@ -45,10 +62,12 @@ type GinHandler interface {
 
 // GinServer implements the http.Server interface using Gin.
 type GinServer struct {
-	engine *gin.Engine
-	server *http.Server
-	config *Config
-	logger log.Logger
+	engine   *gin.Engine
+	server   *http.Server
+	listener net.Listener        // Set on start, or replaces the listener on http.host/http.port
+	certs    *tlsreload.Reloader // Set with http.tls.enabled
+	config   *Config
+	logger   log.Logger
 }
 
 // HttpServerParams contains all dependencies needed to run the HTTP server.
@@ -58,6 +77,14 @@ type HttpServerParams struct {
 	Logger    log.Logger
 	Config    *Config
 	Handlers  []GinHandler `group:"http.handlers"`
+
+	// Middleware contributed with AsGinMiddleware and httpmodule.AsMiddleware,
+	// installed by order with the built-in ones
+	GinMiddleware []GinMiddleware                `group:"httpgin.middleware"`
+	Middleware    []httpmodule.OrderedMiddleware `group:"http.middleware"`
+
+	// Listener replaces the listener on http.host/http.port, e.g. to share a port with gRPC
+	Listener net.Listener `name:"http.listener" optional:"true"`
 }
 
 // NewConfig creates a new Gin HTTP configuration from Viper.
@@ -67,7 +94,13 @@ func NewConfig(v *viper.Viper) *Config {
 			Port: 8080,
 			Host: "",
 		},
-		Mode: gin.ReleaseMode,
+		Mode:              gin.ReleaseMode,
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		TLS: TLSConfig{
+			ReloadInterval: 30 * time.Second,
+		},
+		Middleware: defaultMiddlewareConfig(),
 	}
 
 	// Load configuration from viper
@@ -78,7 +111,8 @@ func NewConfig(v *viper.Viper) *Config {
 	return cfg
 }
 
-// NewGinServer creates a new Gin server instance.
+// NewGinServer creates a new Gin server instance. The engine has no
+// middleware; RunHttpServer installs those enabled under http.middleware.
 func NewGinServer(config *Config, logger log.Logger) *GinServer {
 	// Set Gin mode
 	gin.SetMode(config.Mode)
@@ -86,9 +120,6 @@ func NewGinServer(config *Config, logger log.Logger) *GinServer {
 	// Create Gin engine
 	engine := gin.New()
 
-	// Add default middleware
-	engine.Use(gin.Recovery())
-
 	return &GinServer{
 		engine: engine,
 		config: config,
@@ -98,16 +129,45 @@ func NewGinServer(config *Config, logger log.Logger) *GinServer {
 
 // Start implements http.Server.Start
 func (s *GinServer) Start(ctx context.Context) error {
+	if s.listener == nil {
+		listener, err := net.Listen("tcp", s.Addr())
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", s.Addr(), err)
+		}
+		s.listener = listener
+	}
+
+	var handler http.Handler = s.engine
+	if s.config.H2C {
+		handler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: s.config.IdleTimeout})
+	}
+
 	addr := s.Addr()
 	s.server = &http.Server{
-		Addr:    addr,
-		Handler: s.engine,
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       s.config.ReadTimeout,
+		ReadHeaderTimeout: s.config.ReadHeaderTimeout,
+		WriteTimeout:      s.config.WriteTimeout,
+		IdleTimeout:       s.config.IdleTimeout,
+		MaxHeaderBytes:    s.config.MaxHeaderBytes,
 	}
 
-	s.logger.Info("Starting Gin HTTP server", log.Field{Key: "address", Value: addr})
+	listener := s.listener
+	if s.certs != nil {
+		s.server.TLSConfig = serverTLSConfig(s.certs)
+		listener = tls.NewListener(listener, s.server.TLSConfig)
+		s.certs.Start()
+	}
+
+	s.logger.Info("Starting Gin HTTP server",
+		log.Field{Key: "address", Value: addr},
+		log.Field{Key: "tls", Value: s.certs != nil},
+		log.Field{Key: "h2c", Value: s.config.H2C},
+	)
 
 	go func() {
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			s.logger.Error("Gin HTTP server error", err, log.Field{Key: "address", Value: addr})
 		}
 	}()
@@ -115,23 +175,30 @@ func (s *GinServer) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop implements http.Server.Stop
+// Stop implements http.Server.Stop. In-flight requests are drained until ctx
+// expires, after which remaining connections are closed.
 func (s *GinServer) Stop(ctx context.Context) error {
 	s.logger.Info("Stopping Gin HTTP server", log.Field{Key: "address", Value: s.Addr()})
 
 	if s.server == nil {
 		return nil
 	}
+	if s.certs != nil {
+		defer s.certs.Close()
+	}
 
-	// Create a timeout context for graceful shutdown
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	return s.server.Shutdown(shutdownCtx)
+	if err := s.server.Shutdown(ctx); err != nil {
+		_ = s.server.Close()
+		return err
+	}
+	return nil
 }
 
 // Addr implements http.Server.Addr
 func (s *GinServer) Addr() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
 	if s.config.Host != "" {
 		return fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
 	}
@@ -145,8 +212,38 @@ func (s *GinServer) Engine() *gin.Engine {
 }
 
 // RunHttpServer starts the HTTP server with registered handlers.
+// The built-in middleware enabled under http.middleware and those contributed
+// with AsGinMiddleware and httpmodule.AsMiddleware are installed by order
+// before the handlers register their routes. With http.tls.enabled
+// the server only accepts TLS connections, negotiating HTTP/2 or HTTP/1.1 and
+// reloading its certificates when they change; with http.h2c it also serves
+// HTTP/2 over plaintext connections.
 // This is invoked by Fx during application startup.
-func RunHttpServer(p HttpServerParams, server *GinServer) {
+func RunHttpServer(p HttpServerParams, server *GinServer) error {
+	server.listener = p.Listener
+	if p.Config.TLS.Enabled {
+		if p.Listener != nil {
+			return fmt.Errorf("http.tls cannot be used on a shared listener: terminate TLS in front of the shared port")
+		}
+		if p.Config.H2C {
+			return fmt.Errorf("http.h2c cannot be used with http.tls, which negotiates HTTP/2 itself")
+		}
+		certs, err := tlsreload.NewReloader("http.tls", p.Config.TLS, p.Logger)
+		if err != nil {
+			return err
+		}
+		server.certs = certs
+	}
+	if err := configureClientIP(server.engine, p.Config.Middleware.RealIP); err != nil {
+		return err
+	}
+
+	handlers, err := buildMiddleware(p.Config.Middleware, p.Logger, p.GinMiddleware, p.Middleware)
+	if err != nil {
+		return err
+	}
+	server.engine.Use(handlers...)
+
 	// Register all provided handlers
 	for _, handler := range p.Handlers {
 		handler.RegisterRoutes(server.engine)
@@ -160,6 +257,7 @@ func RunHttpServer(p HttpServerParams, server *GinServer) {
 			return server.Stop(ctx)
 		},
 	})
+	return nil
 }
 
 // AsGinHandler is a generic helper to provide a Gin HTTP handler to the Fx graph.
@@ -0,0 +1,90 @@
+package httpgin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"github.com/things-kit/module/log"
+)
+
+// RequestIDConfig holds the settings of the RequestID middleware.
+type RequestIDConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Header  string `mapstructure:"header"` // Request and response header carrying the ID
+}
+
+// maxRequestIDLength bounds the length of request IDs accepted from clients.
+const maxRequestIDLength = 128
+
+// requestIDKey is the context key holding the request ID.
+type requestIDKey struct{}
+
+// RequestID propagates the request ID in header (X-Request-ID by default),
+// generating one when the request has none or an invalid one. The ID is
+// echoed in the response header and put into the request context, both for
+// RequestIDFrom and as a "request_id" field of every entry logged with the
+// context. It is installed at OrderRequestID with
+// http.middleware.request_id.enabled.
+func RequestID(header string) gin.HandlerFunc {
+	if header == "" {
+		header = "X-Request-ID"
+	}
+	return func(c *gin.Context) {
+		id := c.GetHeader(header)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Header(header, id)
+		ctx := context.WithValue(c.Request.Context(), requestIDKey{}, id)
+		ctx = log.WithFields(ctx, log.Field{Key: "request_id", Value: id})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// RequestIDFrom returns the request ID set by the RequestID middleware.
+//
+// Example:
+//
+//	id, _ := httpgin.RequestIDFrom(c.Request.Context())
+//	req.Header.Set("X-Request-ID", id) // Propagate to a downstream service
+func RequestIDFrom(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// validRequestID reports whether a client-supplied ID is safe to log and echo.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random version 4 UUID.
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
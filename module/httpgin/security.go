@@ -0,0 +1,136 @@
+package httpgin
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SecurityHeadersConfig holds the settings of the SecurityHeaders middleware.
+// Empty values leave the header out.
+type SecurityHeadersConfig struct {
+	Enabled               bool          `mapstructure:"enabled"`
+	ContentTypeOptions    string        `mapstructure:"content_type_options"`    // X-Content-Type-Options
+	FrameOptions          string        `mapstructure:"frame_options"`           // X-Frame-Options
+	ReferrerPolicy        string        `mapstructure:"referrer_policy"`         // Referrer-Policy
+	ContentSecurityPolicy string        `mapstructure:"content_security_policy"` // Content-Security-Policy
+	HSTSMaxAge            time.Duration `mapstructure:"hsts_max_age"`            // Strict-Transport-Security max-age (0 = no header)
+	HSTSIncludeSubdomains bool          `mapstructure:"hsts_include_subdomains"`
+	HSTSPreload           bool          `mapstructure:"hsts_preload"`
+}
+
+// RealIPConfig holds the settings of the RealIP middleware.
+type RealIPConfig struct {
+	Enabled        bool     `mapstructure:"enabled"`
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // IPs or CIDRs whose forwarding headers are trusted
+	Headers        []string `mapstructure:"headers"`         // Headers carrying the client IP, in order of preference
+}
+
+// BodyLimitConfig holds the settings of the BodyLimit middleware.
+type BodyLimitConfig struct {
+	Enabled  bool  `mapstructure:"enabled"`
+	MaxBytes int64 `mapstructure:"max_bytes"`
+}
+
+// SecurityHeaders sets the configured security headers on every response.
+// It is installed at OrderSecurityHeaders with
+// http.middleware.security_headers.enabled.
+func SecurityHeaders(cfg SecurityHeadersConfig) gin.HandlerFunc {
+	headers := map[string]string{
+		"X-Content-Type-Options":  cfg.ContentTypeOptions,
+		"X-Frame-Options":         cfg.FrameOptions,
+		"Referrer-Policy":         cfg.ReferrerPolicy,
+		"Content-Security-Policy": cfg.ContentSecurityPolicy,
+	}
+	if cfg.HSTSMaxAge > 0 {
+		hsts := "max-age=" + strconv.FormatInt(int64(cfg.HSTSMaxAge.Seconds()), 10)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			hsts += "; preload"
+		}
+		headers["Strict-Transport-Security"] = hsts
+	}
+	for name, value := range headers {
+		if value == "" {
+			delete(headers, name)
+		}
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		for name, value := range headers {
+			h.Set(name, value)
+		}
+		c.Next()
+	}
+}
+
+// BodyLimit rejects requests whose body is larger than maxBytes with a 413 in
+// the google.rpc.Status format of WriteStatus. Bodies of unknown length are
+// cut off at maxBytes, failing their reads with an *http.MaxBytesError. It
+// is installed at OrderBodyLimit with http.middleware.body_limit.enabled.
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			WriteStatus(c, http.StatusRequestEntityTooLarge,
+				status.Newf(codes.InvalidArgument, "request body exceeds %d bytes", maxBytes))
+			return
+		}
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
+		c.Next()
+	}
+}
+
+// RealIP replaces the remote address of requests with the client IP resolved
+// by gin's ClientIP, so that handlers and net/http middleware reading
+// RemoteAddr see the client rather than the proxy. It is installed at
+// OrderRealIP with http.middleware.real_ip.enabled, which also makes
+// ClientIP trust the forwarding headers (real_ip.headers) of
+// real_ip.trusted_proxies only.
+func RealIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		if ip != "" {
+			_, port, err := net.SplitHostPort(c.Request.RemoteAddr)
+			if err != nil {
+				port = "0"
+			}
+			c.Request.RemoteAddr = net.JoinHostPort(ip, port)
+		}
+		c.Next()
+	}
+}
+
+// configureClientIP sets which proxies ClientIP trusts. Without
+// http.middleware.real_ip, forwarding headers are ignored and ClientIP is
+// the peer address.
+func configureClientIP(engine *gin.Engine, cfg RealIPConfig) error {
+	if !cfg.Enabled {
+		return engine.SetTrustedProxies(nil)
+	}
+	if len(cfg.TrustedProxies) == 0 {
+		return fmt.Errorf("http.middleware.real_ip requires trusted_proxies")
+	}
+	if err := engine.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid http.middleware.real_ip.trusted_proxies: %w", err)
+	}
+	headers := make([]string, 0, len(cfg.Headers))
+	for _, h := range cfg.Headers {
+		if h = strings.TrimSpace(h); h != "" {
+			headers = append(headers, h)
+		}
+	}
+	engine.RemoteIPHeaders = headers
+	return nil
+}
@@ -0,0 +1,270 @@
+package httpgin_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/httpgin"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"golang.org/x/net/http2"
+)
+
+// testCA issues certificates for tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key signed by the CA
+func (ca *testCA) issue(t *testing.T, serial int64, cn string, spiffe string) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if spiffe != "" {
+		u, err := url.Parse(spiffe)
+		require.NoError(t, err)
+		tmpl.URIs = []*url.URL{u}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+type protoHandler struct{}
+
+func (protoHandler) RegisterRoutes(engine *gin.Engine) {
+	engine.GET("/proto", func(c *gin.Context) {
+		identity := ""
+		if id, ok := httpgin.ClientIdentityFromRequest(c.Request); ok {
+			identity = id.SPIFFEID
+		}
+		c.JSON(http.StatusOK, gin.H{"proto": c.Request.Proto, "identity": identity})
+	})
+	engine.GET("/slow", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+}
+
+// startServer starts httpgin with the given settings under http
+func startServer(t *testing.T, settings map[string]any) (*fxtest.App, *httpgin.GinServer) {
+	gin.SetMode(gin.TestMode)
+	v := viper.New()
+	v.Set("http.host", "127.0.0.1")
+	v.Set("http.port", 0)
+	for key, value := range settings {
+		v.Set("http."+key, value)
+	}
+
+	var server *httpgin.GinServer
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		httpgin.Module,
+		httpgin.AsGinHandler(func() protoHandler { return protoHandler{} }),
+		fx.Populate(&server),
+	)
+	app.RequireStart()
+	return app, server
+}
+
+// TestMutualTLS verifies HTTP/2 over mTLS, client identity, and certificate reload
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+
+	writeServerCert := func(serial int64) {
+		certPEM, keyPEM := ca.issue(t, serial, "server", "")
+		require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+		require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	}
+	writeServerCert(10)
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+
+	app, server := startServer(t, map[string]any{
+		"tls.enabled":         true,
+		"tls.cert_file":       certFile,
+		"tls.key_file":        keyFile,
+		"tls.client_ca_file":  caFile,
+		"tls.reload_interval": "20ms",
+	})
+	defer app.RequireStop()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	clientCertPEM, clientKeyPEM := ca.issue(t, 20, "frontend", "spiffe://example.org/ns/web/sa/frontend")
+	clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	require.NoError(t, err)
+
+	// get requests /proto and returns the serial of the server certificate
+	get := func(certs ...tls.Certificate) (*big.Int, string, error) {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: certs},
+			ForceAttemptHTTP2: true,
+		}}
+		defer client.CloseIdleConnections()
+		resp, err := client.Get("https://" + server.Addr() + "/proto")
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.TLS.PeerCertificates[0].SerialNumber, string(body), nil
+	}
+
+	serial, body, err := get(clientCert)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), serial.Int64())
+	assert.JSONEq(t, `{"proto": "HTTP/2.0", "identity": "spiffe://example.org/ns/web/sa/frontend"}`, body)
+
+	_, _, err = get()
+	assert.Error(t, err, "clients without a certificate must be rejected")
+
+	// Rotate the server certificate on disk
+	time.Sleep(10 * time.Millisecond)
+	writeServerCert(11)
+	assert.Eventually(t, func() bool {
+		serial, _, err := get(clientCert)
+		return err == nil && serial.Int64() == 11
+	}, 2*time.Second, 25*time.Millisecond)
+}
+
+// TestH2C verifies HTTP/2 over plaintext connections
+func TestH2C(t *testing.T) {
+	app, server := startServer(t, map[string]any{"h2c": true})
+	defer app.RequireStop()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+	resp, err := client.Get("http://" + server.Addr() + "/proto")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"proto": "HTTP/2.0", "identity": ""}`, string(body))
+
+	// HTTP/1.1 clients keep working
+	resp, err = http.Get("http://" + server.Addr() + "/proto")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "HTTP/1.1", resp.Proto)
+}
+
+// TestReadHeaderTimeout verifies that connections sending headers too slowly are closed
+func TestReadHeaderTimeout(t *testing.T) {
+	app, server := startServer(t, map[string]any{"read_header_timeout": "100ms"})
+	defer app.RequireStop()
+
+	conn, err := net.Dial("tcp", server.Addr())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = io.WriteString(conn, "GET /proto HTTP/1.1\r\nHost: localhost\r\n")
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	start := time.Now()
+	_, err = bufio.NewReader(conn).ReadString('\n')
+	assert.Error(t, err, "the server must close the connection without a response")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+// TestStopHonorsDeadline verifies that in-flight requests cannot block shutdown past the OnStop deadline
+func TestStopHonorsDeadline(t *testing.T) {
+	app, server := startServer(t, nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := http.Get("http://" + server.Addr() + "/slow")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.Error(t, app.Stop(ctx), "the expired deadline must be reported")
+	assert.Less(t, time.Since(start), time.Second, "shutdown must not wait for the request")
+	<-done
+}
+
+// TestTLSOnSharedListener verifies that TLS is rejected when the server uses a shared listener
+func TestTLSOnSharedListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	v := viper.New()
+	v.Set("http.tls.enabled", true)
+	err = fx.New(
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		fx.Provide(fx.Annotate(func() net.Listener { return l }, fx.ResultTags(`name:"http.listener"`))),
+		httpgin.Module,
+	).Err()
+	assert.ErrorContains(t, err, "shared listener")
+}
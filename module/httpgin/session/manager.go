@@ -0,0 +1,346 @@
+// Package session provides server-side sessions for httpgin.
+// Session data is stored in any cache.Cache implementation; the client only
+// holds a signed cookie carrying the session identifier.
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/things-kit/module/cache"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+)
+
+// Module provides the session manager to the application.
+// It requires a cache.Cache (e.g. from the redis module) to store sessions.
+var Module = fx.Module("session",
+	fx.Provide(
+		NewConfig,
+		NewManager,
+	),
+)
+
+// minSecretLength is the minimum length of a signing secret in bytes.
+const minSecretLength = 32
+
+// Config holds the session configuration.
+type Config struct {
+	CookieName      string        `mapstructure:"cookie_name"`
+	Secrets         []string      `mapstructure:"secrets"`          // The first signs cookies; all verify them
+	IdleTimeout     time.Duration `mapstructure:"idle_timeout"`     // Expire after this long without requests (0 = never)
+	AbsoluteTimeout time.Duration `mapstructure:"absolute_timeout"` // Expire this long after creation regardless of activity
+	KeyPrefix       string        `mapstructure:"key_prefix"`       // Prepended to session IDs in the cache
+	Path            string        `mapstructure:"path"`
+	Domain          string        `mapstructure:"domain"`
+	Secure          bool          `mapstructure:"secure"`
+	SameSite        string        `mapstructure:"same_site"` // lax, strict, none
+}
+
+// NewConfig creates a new session configuration from Viper.
+func NewConfig(v *viper.Viper) *Config {
+	cfg := &Config{
+		CookieName:      "session",
+		IdleTimeout:     30 * time.Minute,
+		AbsoluteTimeout: 12 * time.Hour,
+		KeyPrefix:       "session:",
+		Path:            "/",
+		Secure:          true,
+		SameSite:        "lax",
+	}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("http.session", cfg)
+	}
+
+	return cfg
+}
+
+// record is the stored form of a session.
+type record struct {
+	Created time.Time                  `json:"created"`
+	Values  map[string]json.RawMessage `json:"values"`
+}
+
+// Manager loads and stores sessions for requests passing through its middleware.
+type Manager struct {
+	cfg      *Config
+	cache    cache.Cache
+	logger   log.Logger
+	secrets  [][]byte
+	sameSite http.SameSite
+}
+
+// NewManager creates a session manager storing sessions in c.
+// It fails if no signing secret of at least 32 bytes is configured, or if the
+// timeouts would expire every session immediately.
+func NewManager(cfg *Config, c cache.Cache, logger log.Logger) (*Manager, error) {
+	if len(cfg.Secrets) == 0 {
+		return nil, fmt.Errorf("http.session.secrets must contain at least one secret")
+	}
+	if cfg.AbsoluteTimeout <= 0 {
+		return nil, fmt.Errorf("http.session.absolute_timeout must be positive")
+	}
+	if cfg.IdleTimeout < 0 {
+		return nil, fmt.Errorf("http.session.idle_timeout must not be negative")
+	}
+
+	m := &Manager{cfg: cfg, cache: c, logger: logger}
+	for i, secret := range cfg.Secrets {
+		if len(secret) < minSecretLength {
+			return nil, fmt.Errorf("http.session.secrets[%d] must be at least %d bytes", i, minSecretLength)
+		}
+		m.secrets = append(m.secrets, []byte(secret))
+	}
+
+	switch strings.ToLower(cfg.SameSite) {
+	case "", "lax":
+		m.sameSite = http.SameSiteLaxMode
+	case "strict":
+		m.sameSite = http.SameSiteStrictMode
+	case "none":
+		m.sameSite = http.SameSiteNoneMode
+	default:
+		return nil, fmt.Errorf("unknown http.session.same_site %q", cfg.SameSite)
+	}
+
+	return m, nil
+}
+
+// Middleware loads the request's session before the handler runs, and stores it
+// and issues the cookie just before the response is written.
+// Sessions that are never modified are not stored and get no cookie.
+//
+// Example:
+//
+//	admin := engine.Group("/admin", sessions.Middleware())
+func (m *Manager) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s := m.load(c)
+		c.Set(contextKey, s)
+
+		w := &sessionWriter{ResponseWriter: c.Writer}
+		w.commit = func() { m.commit(c, w.ResponseWriter, s) }
+		c.Writer = w
+
+		c.Next()
+
+		// Handlers that write nothing still need the session stored
+		w.commitOnce()
+		c.Writer = w.ResponseWriter
+	}
+}
+
+// load returns the session named by the request's cookie, or a new session.
+func (m *Manager) load(c *gin.Context) *Session {
+	s := &Session{values: make(map[string]json.RawMessage)}
+
+	if cookie, err := c.Cookie(m.cfg.CookieName); err == nil {
+		if id, current, ok := m.verify(cookie); ok {
+			if rec, ok := m.read(c, id); ok {
+				s.id = id
+				s.created = rec.Created
+				if rec.Values != nil {
+					s.values = rec.Values
+				}
+				// Re-sign cookies signed with a retired secret
+				s.cookieStale = !current
+				return s
+			}
+		}
+	}
+
+	s.id = newID()
+	s.created = time.Now()
+	s.isNew = true
+	s.cookieStale = true
+	return s
+}
+
+// read fetches a stored session, discarding it if the absolute timeout passed.
+func (m *Manager) read(c *gin.Context, id string) (record, bool) {
+	ctx := c.Request.Context()
+
+	var rec record
+	data, err := m.cache.GetBytes(ctx, m.cfg.KeyPrefix+id)
+	if err != nil {
+		if !errors.Is(err, cache.ErrNotFound) {
+			m.logger.ErrorC(ctx, "Failed to load session", err)
+			_ = c.Error(err)
+		}
+		return rec, false
+	}
+
+	if err := json.Unmarshal(data, &rec); err != nil {
+		m.logger.WarnC(ctx, "Discarding undecodable session", err)
+		return rec, false
+	}
+
+	if time.Since(rec.Created) >= m.cfg.AbsoluteTimeout {
+		_ = m.cache.Delete(ctx, m.cfg.KeyPrefix+id)
+		return rec, false
+	}
+	return rec, true
+}
+
+// commit stores, refreshes or deletes the session and sets its cookie.
+func (m *Manager) commit(c *gin.Context, w http.ResponseWriter, s *Session) {
+	ctx := c.Request.Context()
+
+	if s.previousID != "" {
+		if err := m.cache.Delete(ctx, m.cfg.KeyPrefix+s.previousID); err != nil {
+			m.logger.ErrorC(ctx, "Failed to delete previous session", err)
+			_ = c.Error(err)
+		}
+	}
+
+	ttl := m.ttl(s)
+	if s.destroyed || ttl <= 0 {
+		if !s.isNew || s.previousID != "" {
+			m.expireCookie(w)
+		}
+		return
+	}
+
+	switch {
+	case s.modified:
+		data, err := json.Marshal(record{Created: s.created, Values: s.values})
+		if err == nil {
+			err = m.cache.SetBytes(ctx, m.cfg.KeyPrefix+s.id, data, ttl)
+		}
+		if err != nil {
+			m.logger.ErrorC(ctx, "Failed to store session", err)
+			_ = c.Error(err)
+			return
+		}
+	case s.isNew:
+		// Nothing worth storing
+		return
+	default:
+		// Extend the idle timeout
+		if _, err := m.cache.Expire(ctx, m.cfg.KeyPrefix+s.id, ttl); err != nil {
+			m.logger.ErrorC(ctx, "Failed to refresh session", err)
+			_ = c.Error(err)
+		}
+	}
+
+	if s.cookieStale {
+		m.setCookie(w, s, ttl)
+	}
+}
+
+// ttl returns how long the session may live: the idle timeout, capped by what
+// remains of the absolute timeout.
+func (m *Manager) ttl(s *Session) time.Duration {
+	remaining := m.cfg.AbsoluteTimeout - time.Since(s.created)
+	if m.cfg.IdleTimeout > 0 && m.cfg.IdleTimeout < remaining {
+		return m.cfg.IdleTimeout
+	}
+	return remaining
+}
+
+// setCookie issues the signed session cookie, expiring with the absolute timeout.
+func (m *Manager) setCookie(w http.ResponseWriter, s *Session, ttl time.Duration) {
+	maxAge := m.cfg.AbsoluteTimeout - time.Since(s.created)
+	http.SetCookie(w, &http.Cookie{
+		Name:     m.cfg.CookieName,
+		Value:    s.id + "." + m.sign(m.secrets[0], s.id),
+		Path:     m.cfg.Path,
+		Domain:   m.cfg.Domain,
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   m.cfg.Secure,
+		HttpOnly: true,
+		SameSite: m.sameSite,
+	})
+}
+
+// expireCookie tells the client to drop the session cookie.
+func (m *Manager) expireCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     m.cfg.CookieName,
+		Value:    "",
+		Path:     m.cfg.Path,
+		Domain:   m.cfg.Domain,
+		MaxAge:   -1,
+		Secure:   m.cfg.Secure,
+		HttpOnly: true,
+		SameSite: m.sameSite,
+	})
+}
+
+// verify checks a cookie value's signature and returns the session ID, and
+// whether it was signed with the current secret.
+func (m *Manager) verify(cookie string) (id string, current bool, ok bool) {
+	id, signature, found := strings.Cut(cookie, ".")
+	if !found || id == "" {
+		return "", false, false
+	}
+	for i, secret := range m.secrets {
+		if hmac.Equal([]byte(signature), []byte(m.sign(secret, id))) {
+			return id, i == 0, true
+		}
+	}
+	return "", false, false
+}
+
+// sign returns the HMAC-SHA256 signature of id.
+func (m *Manager) sign(secret []byte, id string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newID returns a random 256-bit session identifier.
+func newID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate session ID: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// sessionWriter commits the session before the first byte of the response is written,
+// while headers can still carry the cookie.
+type sessionWriter struct {
+	gin.ResponseWriter
+	commit    func()
+	committed bool
+}
+
+func (w *sessionWriter) commitOnce() {
+	if !w.committed {
+		w.committed = true
+		w.commit()
+	}
+}
+
+func (w *sessionWriter) WriteHeaderNow() {
+	w.commitOnce()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *sessionWriter) Write(data []byte) (int, error) {
+	w.commitOnce()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *sessionWriter) WriteString(s string) (int, error) {
+	w.commitOnce()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *sessionWriter) Flush() {
+	w.commitOnce()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,143 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contextKey is the gin context key under which the middleware stores the session.
+const contextKey = "things-kit.session"
+
+// Session is the server-side state of one client.
+// Values are JSON-encoded; use typed keys created with NewKey to read and write them.
+// A Session is only valid for the request it was loaded for.
+type Session struct {
+	id      string
+	created time.Time
+	values  map[string]json.RawMessage
+
+	previousID  string // Stored ID to delete on commit after Regenerate or Destroy
+	isNew       bool   // Not yet stored
+	modified    bool   // Values changed
+	cookieStale bool   // Cookie must be (re)issued
+	destroyed   bool
+}
+
+// FromContext returns the session loaded by the middleware, or nil if the
+// route is not behind Manager.Middleware.
+func FromContext(c *gin.Context) *Session {
+	s, _ := c.Get(contextKey)
+	session, _ := s.(*Session)
+	return session
+}
+
+// ID returns the session identifier. It changes after Regenerate.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// CreatedAt returns when the session was created. The absolute timeout counts from here.
+func (s *Session) CreatedAt() time.Time {
+	return s.created
+}
+
+// IsNew reports whether the session was created by this request.
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// Regenerate moves the session to a new identifier, keeping its values, and
+// restarts the absolute timeout. Call it on login and privilege changes to
+// prevent session fixation.
+func (s *Session) Regenerate() {
+	if !s.isNew && s.previousID == "" {
+		s.previousID = s.id
+	}
+	s.id = newID()
+	s.created = time.Now()
+	s.modified = true
+	s.cookieStale = true
+}
+
+// Destroy deletes the session and expires its cookie. Call it on logout.
+func (s *Session) Destroy() {
+	if !s.isNew && s.previousID == "" {
+		s.previousID = s.id
+	}
+	s.values = make(map[string]json.RawMessage)
+	s.destroyed = true
+}
+
+// Clear removes all values but keeps the session.
+func (s *Session) Clear() {
+	if len(s.values) == 0 {
+		return
+	}
+	s.values = make(map[string]json.RawMessage)
+	s.modified = true
+}
+
+// Key is a typed accessor for one session value.
+//
+// Example:
+//
+//	var userID = session.NewKey[int64]("user_id")
+//
+//	func (h *Handler) login(c *gin.Context) {
+//	    s := session.FromContext(c)
+//	    s.Regenerate()
+//	    _ = userID.Set(s, user.ID)
+//	}
+//
+//	func (h *Handler) profile(c *gin.Context) {
+//	    id, ok := userID.Get(session.FromContext(c))
+//	    // ...
+//	}
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates a typed accessor for the value stored under name.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// Name returns the name the value is stored under.
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Get returns the value, or false if it is not set or cannot be decoded as T.
+func (k Key[T]) Get(s *Session) (T, bool) {
+	var value T
+	raw, ok := s.values[k.name]
+	if !ok {
+		return value, false
+	}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return value, false
+	}
+	return value, true
+}
+
+// Set stores the value. It fails only if the value cannot be JSON-encoded.
+func (k Key[T]) Set(s *Session, value T) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode session value %s: %w", k.name, err)
+	}
+	s.values[k.name] = raw
+	s.modified = true
+	return nil
+}
+
+// Delete removes the value.
+func (k Key[T]) Delete(s *Session) {
+	if _, ok := s.values[k.name]; ok {
+		delete(s.values, k.name)
+		s.modified = true
+	}
+}
@@ -0,0 +1,165 @@
+package session_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/httpgin/session"
+	"github.com/things-kit/module/memorycache"
+)
+
+const (
+	oldSecret = "old-secret-old-secret-old-secret-old"
+	newSecret = "new-secret-new-secret-new-secret-new"
+)
+
+var userID = session.NewKey[int64]("user_id")
+
+// newEngine returns an engine with login, whoami and logout routes behind the middleware
+func newEngine(t *testing.T, store *memorycache.MemoryCache, secrets ...string) *gin.Engine {
+	cfg := session.NewConfig(nil)
+	cfg.Secrets = secrets
+	manager, err := session.NewManager(cfg, store, nil)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(manager.Middleware())
+	engine.POST("/login", func(c *gin.Context) {
+		s := session.FromContext(c)
+		s.Regenerate()
+		require.NoError(t, userID.Set(s, 42))
+		c.Status(http.StatusNoContent)
+	})
+	engine.GET("/whoami", func(c *gin.Context) {
+		id, ok := userID.Get(session.FromContext(c))
+		if !ok {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"user_id": id})
+	})
+	engine.POST("/logout", func(c *gin.Context) {
+		session.FromContext(c).Destroy()
+		c.Status(http.StatusNoContent)
+	})
+	return engine
+}
+
+// serve performs a request with an optional cookie and returns the recorder
+func serve(engine *gin.Engine, method, path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+// sessionCookie returns the session cookie set by a response
+func sessionCookie(t *testing.T, rec *httptest.ResponseRecorder) *http.Cookie {
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "session" {
+			return c
+		}
+	}
+	t.Fatal("no session cookie in response")
+	return nil
+}
+
+// TestSessionLifecycle verifies login, authenticated requests and logout
+func TestSessionLifecycle(t *testing.T) {
+	store := memorycache.New(0)
+	engine := newEngine(t, store, newSecret)
+
+	rec := serve(engine, http.MethodGet, "/whoami", nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Empty(t, rec.Result().Cookies(), "unmodified sessions must not set a cookie")
+
+	cookie := sessionCookie(t, serve(engine, http.MethodPost, "/login", nil))
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, 1, store.Len())
+
+	rec = serve(engine, http.MethodGet, "/whoami", cookie)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"user_id":42}`, rec.Body.String())
+
+	// Logging in again must move the session to a new ID
+	renewed := sessionCookie(t, serve(engine, http.MethodPost, "/login", cookie))
+	assert.NotEqual(t, cookie.Value, renewed.Value)
+	assert.Equal(t, http.StatusUnauthorized, serve(engine, http.MethodGet, "/whoami", cookie).Code)
+
+	expired := sessionCookie(t, serve(engine, http.MethodPost, "/logout", renewed))
+	assert.Less(t, expired.MaxAge, 0)
+	assert.Equal(t, 0, store.Len())
+}
+
+// TestSessionSecretRotation verifies that retired secrets still verify and cookies are re-signed
+func TestSessionSecretRotation(t *testing.T) {
+	store := memorycache.New(0)
+	cookie := sessionCookie(t, serve(newEngine(t, store, oldSecret), http.MethodPost, "/login", nil))
+
+	rotated := newEngine(t, store, newSecret, oldSecret)
+	rec := serve(rotated, http.MethodGet, "/whoami", cookie)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	resigned := sessionCookie(t, rec)
+	assert.Equal(t, strings.Split(cookie.Value, ".")[0], strings.Split(resigned.Value, ".")[0])
+	assert.NotEqual(t, cookie.Value, resigned.Value)
+
+	// A cookie signed with an unknown secret is ignored
+	assert.Equal(t, http.StatusUnauthorized, serve(newEngine(t, store, newSecret), http.MethodGet, "/whoami", cookie).Code)
+}
+
+// TestNewManagerErrors verifies that unusable configurations are rejected
+func TestNewManagerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*session.Config)
+		wantErr string
+	}{
+		{
+			name:    "no secret",
+			modify:  func(cfg *session.Config) { cfg.Secrets = nil },
+			wantErr: "http.session.secrets must contain at least one secret",
+		},
+		{
+			name:    "short secret",
+			modify:  func(cfg *session.Config) { cfg.Secrets = []string{"short"} },
+			wantErr: "http.session.secrets[0] must be at least 32 bytes",
+		},
+		{
+			name:    "zero absolute timeout",
+			modify:  func(cfg *session.Config) { cfg.AbsoluteTimeout = 0 },
+			wantErr: "http.session.absolute_timeout must be positive",
+		},
+		{
+			name:    "negative idle timeout",
+			modify:  func(cfg *session.Config) { cfg.IdleTimeout = -time.Minute },
+			wantErr: "http.session.idle_timeout must not be negative",
+		},
+		{
+			name:    "unknown same site",
+			modify:  func(cfg *session.Config) { cfg.SameSite = "sometimes" },
+			wantErr: `unknown http.session.same_site "sometimes"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := session.NewConfig(nil)
+			cfg.Secrets = []string{newSecret}
+			tt.modify(cfg)
+
+			_, err := session.NewManager(cfg, memorycache.New(0), nil)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package httpgin
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/things-kit/module/tlsreload"
+)
+
+// TLSConfig holds the TLS settings of the HTTP server.
+type TLSConfig = tlsreload.Config
+
+// ClientIdentity is the identity of a client that presented a verified certificate.
+type ClientIdentity = tlsreload.ClientIdentity
+
+// ClientIdentityFromRequest returns the identity of the client making the
+// request. It reports false unless the connection uses TLS and the client
+// certificate was verified against http.tls.client_ca_file.
+//
+// Example:
+//
+//	func (h *InternalHandler) sync(c *gin.Context) {
+//	    id, ok := httpgin.ClientIdentityFromRequest(c.Request)
+//	    if !ok || id.SPIFFEID != "spiffe://example.org/ns/jobs/sa/sync" {
+//	        c.AbortWithStatus(http.StatusForbidden)
+//	        return
+//	    }
+//	    // ...
+//	}
+func ClientIdentityFromRequest(r *http.Request) (ClientIdentity, bool) {
+	if r.TLS == nil {
+		return ClientIdentity{}, false
+	}
+	return tlsreload.IdentityFromState(*r.TLS)
+}
+
+// serverTLSConfig returns a TLS configuration that uses the current files of
+// certs for each handshake, negotiating HTTP/2 or HTTP/1.1 with ALPN.
+func serverTLSConfig(certs *tlsreload.Reloader) *tls.Config {
+	return certs.TLSConfig("h2", "http/1.1")
+}
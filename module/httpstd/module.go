@@ -0,0 +1,222 @@
+// Package httpstd provides a net/http implementation of the Things-Kit HTTP
+// server interface. Routes are registered on the standard library's
+// http.ServeMux, with its method and wildcard patterns ("GET /users/{id}"),
+// so handlers depend on nothing but net/http.
+package httpstd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/spf13/viper"
+	httpmodule "github.com/things-kit/module/http"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+)
+
+// Module provides the net/http server module to the application.
+// This module implements the http.Server interface using http.ServeMux.
+var Module = fx.Module("httpstd",
+	fx.Provide(
+		NewConfig,
+		NewServer,
+		fx.Annotate(
+			func(s *Server) httpmodule.Server { return s },
+			fx.As(new(httpmodule.Server)),
+		),
+	),
+	fx.Invoke(RunHttpServer),
+)
+
+// Config holds the net/http server configuration.
+type Config struct {
+	httpmodule.Config `mapstructure:",squash"`
+}
+
+// MuxHandler is a net/http implementation of http.Handler.
+// Handlers that implement this interface can be registered with AsMuxHandler.
+type MuxHandler interface {
+	RegisterRoutes(mux *http.ServeMux)
+}
+
+// Server implements the http.Server interface using http.ServeMux.
+type Server struct {
+	mux      *http.ServeMux
+	handler  http.Handler // mux wrapped with the middleware
+	server   *http.Server
+	listener net.Listener // Set on start, or replaces the listener on http.host/http.port
+	config   *Config
+	logger   log.Logger
+}
+
+// HttpServerParams contains all dependencies needed to run the HTTP server.
+type HttpServerParams struct {
+	fx.In
+	Lifecycle  fx.Lifecycle
+	Logger     log.Logger
+	Config     *Config
+	Handlers   []MuxHandler                   `group:"http.handlers"`
+	Middleware []httpmodule.OrderedMiddleware `group:"http.middleware"`
+
+	// Listener replaces the listener on http.host/http.port, e.g. to share a port with gRPC
+	Listener net.Listener `name:"http.listener" optional:"true"`
+}
+
+// NewConfig creates a new net/http configuration from Viper.
+func NewConfig(v *viper.Viper) *Config {
+	cfg := &Config{
+		Config: httpmodule.Config{
+			Port: 8080,
+			Host: "",
+		},
+	}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("http", cfg)
+	}
+
+	return cfg
+}
+
+// NewServer creates a new net/http server instance.
+func NewServer(config *Config, logger log.Logger) *Server {
+	mux := http.NewServeMux()
+	return &Server{
+		mux:     mux,
+		handler: mux,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// Start implements http.Server.Start
+func (s *Server) Start(ctx context.Context) error {
+	if s.listener == nil {
+		listener, err := net.Listen("tcp", s.Addr())
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", s.Addr(), err)
+		}
+		s.listener = listener
+	}
+
+	addr := s.Addr()
+	s.server = &http.Server{
+		Addr:    addr,
+		Handler: recoverer(s.handler, s.logger),
+	}
+
+	s.logger.Info("Starting HTTP server", log.Field{Key: "address", Value: addr})
+
+	go func() {
+		if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("HTTP server error", err, log.Field{Key: "address", Value: addr})
+		}
+	}()
+
+	return nil
+}
+
+// Stop implements http.Server.Stop. In-flight requests are drained until ctx
+// expires, after which remaining connections are closed.
+func (s *Server) Stop(ctx context.Context) error {
+	s.logger.Info("Stopping HTTP server", log.Field{Key: "address", Value: s.Addr()})
+
+	if s.server == nil {
+		return nil
+	}
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		_ = s.server.Close()
+		return err
+	}
+	return nil
+}
+
+// Addr implements http.Server.Addr
+func (s *Server) Addr() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return net.JoinHostPort(s.config.Host, fmt.Sprint(s.config.Port))
+}
+
+// Mux returns the underlying ServeMux.
+// This is useful for registering routes outside of handlers.
+func (s *Server) Mux() *http.ServeMux {
+	return s.mux
+}
+
+// RunHttpServer starts the HTTP server with registered handlers, wrapping
+// the mux with the middleware contributed with httpmodule.AsMiddleware.
+// This is invoked by Fx during application startup.
+func RunHttpServer(p HttpServerParams, server *Server) {
+	server.listener = p.Listener
+
+	// Register all provided handlers
+	for _, handler := range p.Handlers {
+		handler.RegisterRoutes(server.mux)
+	}
+	server.handler = httpmodule.Chain(server.mux, p.Middleware)
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return server.Start(ctx)
+		},
+		OnStop: func(ctx context.Context) error {
+			return server.Stop(ctx)
+		},
+	})
+}
+
+// AsMuxHandler is a generic helper to provide a net/http handler to the Fx graph.
+// The constructor should return a type that implements the MuxHandler interface.
+//
+// Example:
+//
+//	type UserHandler struct {
+//	    store *UserStore
+//	}
+//
+//	func (h *UserHandler) RegisterRoutes(mux *http.ServeMux) {
+//	    mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+//	        user, err := h.store.Get(r.Context(), r.PathValue("id"))
+//	        ...
+//	    })
+//	}
+//
+//	// In main.go:
+//	httpstd.AsMuxHandler(NewUserHandler)
+func AsMuxHandler(constructor any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			constructor,
+			fx.As(new(MuxHandler)),
+			fx.ResultTags(`group:"http.handlers"`),
+		),
+	)
+}
+
+// recoverer converts handler panics into 500 responses, logging the panic and stack.
+func recoverer(next http.Handler, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if p := recover(); p != nil {
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				logger.ErrorC(r.Context(), "Panic in HTTP handler", fmt.Errorf("panic: %v", p),
+					log.Field{Key: "method", Value: r.Method},
+					log.Field{Key: "path", Value: r.URL.Path},
+					log.Field{Key: "stack", Value: string(debug.Stack())},
+				)
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
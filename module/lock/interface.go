@@ -0,0 +1,167 @@
+// Package lock defines framework-level distributed lock abstractions.
+// This package provides interfaces that lock implementations must satisfy,
+// along with backend-independent helpers for running work under a lock.
+//
+// For a production-ready implementation, see the redis package.
+// For tests, see the locktest package.
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrNotAcquired is returned when a lock is held by another owner.
+	ErrNotAcquired = errors.New("lock: not acquired")
+
+	// ErrNotHeld is returned when releasing or extending a lock that has
+	// expired or been taken over by another owner.
+	ErrNotHeld = errors.New("lock: not held")
+
+	// ErrInvalidTTL is returned when acquiring or extending a lock with a TTL
+	// below one millisecond, the resolution of lock expiry.
+	ErrInvalidTTL = errors.New("lock: ttl must be at least 1ms")
+)
+
+// defaultRetryInterval is used by Retry when it is given a non-positive interval.
+const defaultRetryInterval = 100 * time.Millisecond
+
+// Locker obtains named locks that provide mutual exclusion across processes.
+type Locker interface {
+	// Acquire attempts to obtain the lock for key once.
+	// The lock expires after ttl unless it is extended or released.
+	// Returns ErrNotAcquired if another owner holds the lock, and
+	// ErrInvalidTTL if ttl is below one millisecond.
+	Acquire(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+
+	// TryAcquire retries Acquire until the lock is obtained, wait elapses,
+	// or ctx is done. Returns ErrNotAcquired if wait elapses first.
+	TryAcquire(ctx context.Context, key string, ttl, wait time.Duration) (Lock, error)
+}
+
+// Lock is a held lock. Every lock carries a unique owner token so that only
+// the owner can release or extend it.
+type Lock interface {
+	// Key returns the name of the lock.
+	Key() string
+
+	// Token returns the unique owner token of this lock.
+	Token() string
+
+	// Release gives up the lock.
+	// Returns ErrNotHeld if the lock expired or is now owned by someone else.
+	Release(ctx context.Context) error
+
+	// Extend resets the lock's expiration to ttl from now.
+	// Returns ErrNotHeld if the lock expired or is now owned by someone else,
+	// and ErrInvalidTTL if ttl is below one millisecond.
+	Extend(ctx context.Context, ttl time.Duration) error
+}
+
+// CheckTTL returns ErrInvalidTTL if ttl is below one millisecond.
+// Locker implementations call it before acquiring or extending a lock, since
+// a zero TTL would never expire and a shorter one would delete the lock.
+func CheckTTL(ttl time.Duration) error {
+	if ttl < time.Millisecond {
+		return fmt.Errorf("%w, got %s", ErrInvalidTTL, ttl)
+	}
+	return nil
+}
+
+// Retry calls acquire every interval until it succeeds, returns an error other
+// than ErrNotAcquired, wait elapses, or ctx is done.
+// It is a helper for implementing Locker.TryAcquire. A non-positive interval
+// falls back to 100ms.
+func Retry(ctx context.Context, wait, interval time.Duration, acquire func(ctx context.Context) (Lock, error)) (Lock, error) {
+	deadline := time.Now().Add(wait)
+	if interval <= 0 {
+		interval = defaultRetryInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		l, err := acquire(ctx)
+		if !errors.Is(err, ErrNotAcquired) {
+			return l, err
+		}
+		if !time.Now().Before(deadline) {
+			return nil, ErrNotAcquired
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// KeepAlive extends l every ttl/3 until the returned cancel function is called
+// or ctx is done. The returned context is canceled as soon as an extension
+// fails, so work running under it stops once the lock can no longer be trusted.
+// A non-positive ttl leaves the lock as it is.
+func KeepAlive(ctx context.Context, l Lock, ttl time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if ttl <= 0 {
+		return ctx, cancel
+	}
+
+	interval := ttl / 3
+	if interval <= 0 {
+		interval = ttl
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := l.Extend(ctx, ttl); err != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// WithLock acquires the lock for key, keeps it alive while fn runs, and
+// releases it afterwards. The context passed to fn is canceled if the lock is
+// lost. Returns ErrNotAcquired if another owner holds the lock, and ErrNotHeld
+// if fn succeeded but the lock was lost before it could be released.
+//
+// Example:
+//
+//	err := lock.WithLock(ctx, locker, "jobs:nightly-report", 30*time.Second, func(ctx context.Context) error {
+//	    return generateReport(ctx)
+//	})
+func WithLock(ctx context.Context, locker Locker, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	l, err := locker.Acquire(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+
+	lockCtx, cancel := KeepAlive(ctx, l, ttl)
+	defer cancel()
+
+	fnErr := fn(lockCtx)
+	cancel()
+
+	// Release without ctx's cancellation so the lock is freed even if ctx is done.
+	releaseErr := l.Release(context.WithoutCancel(ctx))
+	if fnErr != nil {
+		return fnErr
+	}
+	return releaseErr
+}
@@ -0,0 +1,143 @@
+// Package locktest provides an in-memory implementation of the lock.Locker
+// interface for tests and single-process applications.
+package locktest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/things-kit/module/lock"
+	"go.uber.org/fx"
+)
+
+// Module provides an in-memory lock.Locker to the application.
+// Use it in place of the redis module's locker in tests.
+var Module = fx.Module("locktest",
+	fx.Provide(
+		NewLocker,
+		fx.Annotate(
+			func(l *Locker) lock.Locker { return l },
+			fx.As(new(lock.Locker)),
+		),
+	),
+)
+
+// holder records the current owner of a lock.
+type holder struct {
+	token     string
+	expiresAt time.Time
+}
+
+// Locker implements lock.Locker using a process-local map.
+// Locks expire after their TTL exactly like the Redis implementation.
+type Locker struct {
+	mu            sync.Mutex
+	held          map[string]holder
+	retryInterval time.Duration
+}
+
+// NewLocker creates a new in-memory locker.
+func NewLocker() *Locker {
+	return &Locker{
+		held:          make(map[string]holder),
+		retryInterval: 10 * time.Millisecond,
+	}
+}
+
+// Acquire attempts to obtain the lock for key once.
+func (l *Locker) Acquire(ctx context.Context, key string, ttl time.Duration) (lock.Lock, error) {
+	if err := lock.CheckTTL(ttl); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if h, ok := l.held[key]; ok && now.Before(h.expiresAt) {
+		return nil, lock.ErrNotAcquired
+	}
+
+	token := newToken()
+	l.held[key] = holder{token: token, expiresAt: now.Add(ttl)}
+	return &memoryLock{locker: l, key: key, token: token}, nil
+}
+
+// TryAcquire retries Acquire until the lock is obtained, wait elapses, or ctx is done.
+func (l *Locker) TryAcquire(ctx context.Context, key string, ttl, wait time.Duration) (lock.Lock, error) {
+	return lock.Retry(ctx, wait, l.retryInterval, func(ctx context.Context) (lock.Lock, error) {
+		return l.Acquire(ctx, key, ttl)
+	})
+}
+
+// IsHeld reports whether key is currently locked by any owner.
+// It is intended for test assertions.
+func (l *Locker) IsHeld(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h, ok := l.held[key]
+	return ok && time.Now().Before(h.expiresAt)
+}
+
+// owns reports whether token currently owns key.
+// The caller must hold l.mu.
+func (l *Locker) owns(key, token string) bool {
+	h, ok := l.held[key]
+	return ok && h.token == token && time.Now().Before(h.expiresAt)
+}
+
+// memoryLock is a lock held in a Locker.
+type memoryLock struct {
+	locker *Locker
+	key    string
+	token  string
+}
+
+// Key returns the name of the lock.
+func (m *memoryLock) Key() string {
+	return m.key
+}
+
+// Token returns the unique owner token of this lock.
+func (m *memoryLock) Token() string {
+	return m.token
+}
+
+// Release gives up the lock if it is still owned.
+func (m *memoryLock) Release(ctx context.Context) error {
+	m.locker.mu.Lock()
+	defer m.locker.mu.Unlock()
+
+	if !m.locker.owns(m.key, m.token) {
+		return lock.ErrNotHeld
+	}
+	delete(m.locker.held, m.key)
+	return nil
+}
+
+// Extend resets the lock's expiration if it is still owned.
+func (m *memoryLock) Extend(ctx context.Context, ttl time.Duration) error {
+	if err := lock.CheckTTL(ttl); err != nil {
+		return err
+	}
+
+	m.locker.mu.Lock()
+	defer m.locker.mu.Unlock()
+
+	if !m.locker.owns(m.key, m.token) {
+		return lock.ErrNotHeld
+	}
+	m.locker.held[m.key] = holder{token: m.token, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// newToken returns a random owner token.
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,122 @@
+package locktest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/lock"
+	"github.com/things-kit/module/lock/locktest"
+)
+
+// TestMutualExclusion verifies that a held lock cannot be acquired or released by another owner
+func TestMutualExclusion(t *testing.T) {
+	ctx := context.Background()
+	locker := locktest.NewLocker()
+
+	first, err := locker.Acquire(ctx, "job", time.Minute)
+	require.NoError(t, err)
+
+	_, err = locker.Acquire(ctx, "job", time.Minute)
+	assert.True(t, errors.Is(err, lock.ErrNotAcquired), "second acquire should fail while held")
+
+	require.NoError(t, first.Release(ctx))
+	assert.True(t, errors.Is(first.Release(ctx), lock.ErrNotHeld), "double release should report not held")
+
+	second, err := locker.Acquire(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Token(), second.Token())
+}
+
+// TestExpiredLockIsNotHeld verifies that an expired lock can be taken over and the old owner loses it
+func TestExpiredLockIsNotHeld(t *testing.T) {
+	ctx := context.Background()
+	locker := locktest.NewLocker()
+
+	stale, err := locker.Acquire(ctx, "job", 10*time.Millisecond)
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = locker.Acquire(ctx, "job", time.Minute)
+	require.NoError(t, err)
+
+	assert.True(t, errors.Is(stale.Extend(ctx, time.Minute), lock.ErrNotHeld))
+	assert.True(t, errors.Is(stale.Release(ctx), lock.ErrNotHeld))
+}
+
+// TestTryAcquireWaits verifies that TryAcquire obtains the lock once the current owner releases it
+func TestTryAcquireWaits(t *testing.T) {
+	ctx := context.Background()
+	locker := locktest.NewLocker()
+
+	held, err := locker.Acquire(ctx, "job", time.Minute)
+	require.NoError(t, err)
+
+	_, err = locker.TryAcquire(ctx, "job", time.Minute, 20*time.Millisecond)
+	assert.True(t, errors.Is(err, lock.ErrNotAcquired), "should give up after wait")
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = held.Release(ctx)
+	}()
+
+	l, err := locker.TryAcquire(ctx, "job", time.Minute, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "job", l.Key())
+}
+
+// TestWithLockKeepsAliveAndReleases verifies that WithLock renews the lock during work and releases it afterwards
+func TestWithLockKeepsAliveAndReleases(t *testing.T) {
+	ctx := context.Background()
+	locker := locktest.NewLocker()
+
+	err := lock.WithLock(ctx, locker, "job", 30*time.Millisecond, func(ctx context.Context) error {
+		// Outlive the TTL; the keep-alive must extend the lock.
+		time.Sleep(60 * time.Millisecond)
+		assert.True(t, locker.IsHeld("job"))
+		return ctx.Err()
+	})
+	require.NoError(t, err)
+	assert.False(t, locker.IsHeld("job"))
+}
+
+// TestInvalidTTL verifies that TTLs below one millisecond are rejected
+func TestInvalidTTL(t *testing.T) {
+	ctx := context.Background()
+	locker := locktest.NewLocker()
+
+	for _, ttl := range []time.Duration{-time.Second, 0, time.Microsecond} {
+		_, err := locker.Acquire(ctx, "job", ttl)
+		assert.True(t, errors.Is(err, lock.ErrInvalidTTL), "acquire with %s", ttl)
+	}
+	assert.False(t, locker.IsHeld("job"))
+
+	held, err := locker.Acquire(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, errors.Is(held.Extend(ctx, 0), lock.ErrInvalidTTL))
+	assert.True(t, locker.IsHeld("job"), "a rejected extension must not release the lock")
+}
+
+// TestHelpersGuardDurations verifies that the helpers do not panic on degenerate intervals and TTLs
+func TestHelpersGuardDurations(t *testing.T) {
+	ctx := context.Background()
+	locker := locktest.NewLocker()
+
+	held, err := locker.Acquire(ctx, "job", time.Minute)
+	require.NoError(t, err)
+
+	_, err = lock.Retry(ctx, 10*time.Millisecond, 0, func(ctx context.Context) (lock.Lock, error) {
+		return locker.Acquire(ctx, "job", time.Minute)
+	})
+	assert.True(t, errors.Is(err, lock.ErrNotAcquired))
+
+	for _, ttl := range []time.Duration{-time.Second, 0, 2} {
+		keepCtx, cancel := lock.KeepAlive(ctx, held, ttl)
+		cancel()
+		<-keepCtx.Done()
+	}
+}
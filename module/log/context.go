@@ -0,0 +1,26 @@
+package log
+
+import "context"
+
+// fieldsKey is the context key holding the fields added with WithFields.
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying fields, in addition to those
+// already in ctx. Context-aware logging methods add them to every entry
+// logged with the context, e.g. the request ID of an HTTP request.
+func WithFields(ctx context.Context, fields ...Field) context.Context {
+	existing := FieldsFrom(ctx)
+	merged := make([]Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFrom returns the fields added to ctx with WithFields.
+func FieldsFrom(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]Field)
+	return fields
+}
@@ -136,9 +136,10 @@ func convertFields(fields []log.Field) []zap.Field {
 }
 
 // extractContextFields extracts structured fields from context.
-// This can be extended to extract trace IDs, request IDs, user IDs, etc.
+// This can be extended to extract trace IDs, user IDs, etc.
 func extractContextFields(ctx context.Context) []zap.Field {
-	var fields []zap.Field
+	// Fields added with log.WithFields, e.g. request IDs
+	fields := convertFields(log.FieldsFrom(ctx))
 
 	// Example: Extract trace ID from context if available
 	// if traceID := trace.SpanContextFromContext(ctx).TraceID(); traceID.IsValid() {
@@ -0,0 +1,312 @@
+// Package memorycache provides an in-process implementation of the cache.Cache interface.
+// It is useful for tests, local development, and as the L1 tier of a layered cache.
+// Entries are evicted lazily when they expire and in least-recently-used order
+// when the cache is bounded.
+package memorycache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/things-kit/module/cache"
+	"go.uber.org/fx"
+)
+
+// Module provides the in-memory cache module to the application.
+// It provides both the cache.Cache interface and the concrete *MemoryCache.
+var Module = fx.Module("memorycache",
+	fx.Provide(
+		NewConfig,
+		NewMemoryCache,
+		// Provide as cache.Cache interface
+		fx.Annotate(
+			func(c *MemoryCache) cache.Cache { return c },
+			fx.As(new(cache.Cache)),
+		),
+	),
+)
+
+// Config holds the in-memory cache configuration.
+type Config struct {
+	MaxEntries int `mapstructure:"max_entries"` // Maximum number of entries (0 = unbounded)
+}
+
+// NewConfig creates a new in-memory cache configuration from Viper.
+func NewConfig(v *viper.Viper) *Config {
+	cfg := &Config{
+		MaxEntries: 0, // Unbounded by default
+	}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("memorycache", cfg)
+	}
+
+	return cfg
+}
+
+// entry is a single cached value tracked in the LRU list.
+type entry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // Zero means no expiration
+	tags      []string  // Tags the key was stored with
+}
+
+// expired reports whether the entry has expired at the given time.
+func (e *entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryCache implements the cache.BatchCache, cache.TaggedCache and
+// cache.PatternCache interfaces using a process-local map.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[string]*list.Element
+	lru        *list.List                     // Front is most recently used
+	tags       map[string]map[string]struct{} // Tag to tagged keys; entries hold the reverse index
+}
+
+// New creates an in-memory cache holding at most maxEntries entries.
+// A maxEntries of 0 or less means the cache is unbounded.
+func New(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		lru:        list.New(),
+		tags:       make(map[string]map[string]struct{}),
+	}
+}
+
+// NewMemoryCache creates a new in-memory cache from configuration.
+func NewMemoryCache(cfg *Config) *MemoryCache {
+	return New(cfg.MaxEntries)
+}
+
+// Get retrieves the value for the given key.
+func (c *MemoryCache) Get(ctx context.Context, key string) (string, error) {
+	value, err := c.GetBytes(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// Set stores a value with the given key and expiration duration.
+func (c *MemoryCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	return c.SetBytes(ctx, key, []byte(value), expiration)
+}
+
+// Delete removes the key from the cache.
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(key)
+	return nil
+}
+
+// Exists checks if a key exists in the cache.
+func (c *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lookup(key, time.Now()) != nil, nil
+}
+
+// GetBytes retrieves the raw byte value for the given key.
+func (c *MemoryCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.lookup(key, time.Now())
+	if e == nil {
+		return nil, cache.ErrNotFound
+	}
+	c.lru.MoveToFront(c.items[key])
+
+	value := make([]byte, len(e.value))
+	copy(value, e.value)
+	return value, nil
+}
+
+// SetBytes stores a raw byte value with the given key and expiration.
+func (c *MemoryCache) SetBytes(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.store(key, value, expiration, time.Now())
+	return nil
+}
+
+// Expire sets a timeout on a key.
+// As with Redis, a non-positive expiration deletes the key immediately.
+func (c *MemoryCache) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	e := c.lookup(key, now)
+	if e == nil {
+		return false, nil
+	}
+	if expiration <= 0 {
+		c.remove(key)
+		return true, nil
+	}
+	e.expiresAt = now.Add(expiration)
+	return true, nil
+}
+
+// TTL returns the remaining time to live of a key.
+// It follows the Redis convention of -1 for keys without expiration
+// and -2 for missing keys.
+func (c *MemoryCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	e := c.lookup(key, now)
+	if e == nil {
+		return -2, nil
+	}
+	if e.expiresAt.IsZero() {
+		return -1, nil
+	}
+	return e.expiresAt.Sub(now), nil
+}
+
+// Ping tests connectivity to the cache backend.
+// An in-memory cache is always available.
+func (c *MemoryCache) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close releases all entries held by the cache.
+func (c *MemoryCache) Close() error {
+	c.Flush()
+	return nil
+}
+
+// MGet retrieves multiple values at once.
+func (c *MemoryCache) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	result := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if e := c.lookup(key, now); e != nil {
+			c.lru.MoveToFront(c.items[key])
+			result[key] = string(e.value)
+		}
+	}
+	return result, nil
+}
+
+// MSet sets multiple key-value pairs at once.
+func (c *MemoryCache) MSet(ctx context.Context, pairs map[string]string, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, value := range pairs {
+		c.store(key, []byte(value), expiration, now)
+	}
+	return nil
+}
+
+// MDelete removes multiple keys at once.
+func (c *MemoryCache) MDelete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		c.remove(key)
+	}
+	return nil
+}
+
+// Len returns the number of entries currently held, including expired
+// entries that have not been evicted yet.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// Flush removes all entries from the cache.
+func (c *MemoryCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*list.Element)
+	c.lru.Init()
+	c.tags = make(map[string]map[string]struct{})
+}
+
+// lookup returns the live entry for key, evicting it if it has expired.
+// The caller must hold c.mu.
+func (c *MemoryCache) lookup(key string, now time.Time) *entry {
+	elem, ok := c.items[key]
+	if !ok {
+		return nil
+	}
+	e := elem.Value.(*entry)
+	if e.expired(now) {
+		c.remove(key)
+		return nil
+	}
+	return e
+}
+
+// store inserts or replaces the entry for key and enforces the size bound.
+// A replaced entry loses its tags. The caller must hold c.mu.
+func (c *MemoryCache) store(key string, value []byte, expiration time.Duration, now time.Time) *entry {
+	e := &entry{key: key, value: make([]byte, len(value))}
+	copy(e.value, value)
+	if expiration > 0 {
+		e.expiresAt = now.Add(expiration)
+	}
+
+	if elem, ok := c.items[key]; ok {
+		c.untag(elem.Value.(*entry))
+		elem.Value = e
+		c.lru.MoveToFront(elem)
+		return e
+	}
+	c.items[key] = c.lru.PushFront(e)
+
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.remove(oldest.Value.(*entry).key)
+	}
+	return e
+}
+
+// remove deletes key from the cache if present.
+// The caller must hold c.mu.
+func (c *MemoryCache) remove(key string) {
+	if elem, ok := c.items[key]; ok {
+		c.untag(elem.Value.(*entry))
+		c.lru.Remove(elem)
+		delete(c.items, key)
+	}
+}
+
+// untag drops the entry's key from every tag it carries.
+// The caller must hold c.mu.
+func (c *MemoryCache) untag(e *entry) {
+	for _, tag := range e.tags {
+		keys := c.tags[tag]
+		delete(keys, e.key)
+		if len(keys) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
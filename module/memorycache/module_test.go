@@ -0,0 +1,150 @@
+package memorycache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/cache"
+	"github.com/things-kit/module/memorycache"
+)
+
+// TestGetSetDelete verifies the basic key-value round trip and the not-found error
+func TestGetSetDelete(t *testing.T) {
+	ctx := context.Background()
+	c := memorycache.New(0)
+
+	_, err := c.Get(ctx, "missing")
+	assert.True(t, errors.Is(err, cache.ErrNotFound), "missing key should return cache.ErrNotFound")
+
+	require.NoError(t, c.Set(ctx, "key", "value", 0))
+	value, err := c.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	require.NoError(t, c.Delete(ctx, "key"))
+	exists, err := c.Exists(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+// TestExpiration verifies that expired entries are not returned and TTL follows Redis conventions
+func TestExpiration(t *testing.T) {
+	ctx := context.Background()
+	c := memorycache.New(0)
+
+	require.NoError(t, c.Set(ctx, "short", "value", 10*time.Millisecond))
+	require.NoError(t, c.Set(ctx, "forever", "value", 0))
+
+	ttl, err := c.TTL(ctx, "forever")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(-1), ttl)
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = c.Get(ctx, "short")
+	assert.True(t, errors.Is(err, cache.ErrNotFound), "expired key should return cache.ErrNotFound")
+
+	ttl, err = c.TTL(ctx, "short")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(-2), ttl)
+}
+
+// TestLRUEviction verifies that a bounded cache evicts the least recently used entry
+func TestLRUEviction(t *testing.T) {
+	ctx := context.Background()
+	c := memorycache.New(2)
+
+	require.NoError(t, c.Set(ctx, "a", "1", 0))
+	require.NoError(t, c.Set(ctx, "b", "2", 0))
+
+	// Touch "a" so that "b" becomes the eviction candidate
+	_, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set(ctx, "c", "3", 0))
+
+	values, err := c.MGet(ctx, "a", "b", "c")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "c": "3"}, values)
+	assert.Equal(t, 2, c.Len())
+}
+
+// TestInvalidateTag verifies that invalidating a tag deletes only the keys carrying it
+func TestInvalidateTag(t *testing.T) {
+	ctx := context.Background()
+	c := memorycache.New(0)
+
+	require.NoError(t, c.SetWithTags(ctx, "user:42:profile", "p", 0, "user:42"))
+	require.NoError(t, c.SetWithTags(ctx, "user:42:orders", "o", 0, "user:42", "orders"))
+	require.NoError(t, c.SetWithTags(ctx, "user:7:profile", "p", 0, "user:7"))
+
+	require.NoError(t, c.InvalidateTag(ctx, "user:42"))
+
+	values, err := c.MGet(ctx, "user:42:profile", "user:42:orders", "user:7:profile")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"user:7:profile": "p"}, values)
+}
+
+// TestTagsFollowKeys verifies that a key leaves its tags when it is deleted, evicted, expired or overwritten
+func TestTagsFollowKeys(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		detach func(c *memorycache.MemoryCache)
+	}{
+		{name: "overwrite", detach: func(c *memorycache.MemoryCache) {}},
+		{name: "delete", detach: func(c *memorycache.MemoryCache) {
+			_ = c.Delete(ctx, "k")
+		}},
+		{name: "eviction", detach: func(c *memorycache.MemoryCache) {
+			_ = c.Set(ctx, "other", "v", 0)
+		}},
+		{name: "expiry", detach: func(c *memorycache.MemoryCache) {
+			_, _ = c.Expire(ctx, "k", time.Millisecond)
+			time.Sleep(5 * time.Millisecond)
+			_, _ = c.Exists(ctx, "k")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := memorycache.New(1)
+			require.NoError(t, c.SetWithTags(ctx, "k", "tagged", 0, "t"))
+			tt.detach(c)
+			require.NoError(t, c.Set(ctx, "k", "untagged", 0))
+
+			require.NoError(t, c.InvalidateTag(ctx, "t"))
+			value, err := c.Get(ctx, "k")
+			require.NoError(t, err)
+			assert.Equal(t, "untagged", value, "a key re-set without the tag must survive invalidation")
+		})
+	}
+}
+
+// TestDeletePattern verifies glob matching with Redis semantics
+func TestDeletePattern(t *testing.T) {
+	ctx := context.Background()
+	c := memorycache.New(0)
+
+	for _, key := range []string{"session:a/1", "session:b/2", "sessions", "user:1"} {
+		require.NoError(t, c.Set(ctx, key, "v", 0))
+	}
+
+	deleted, err := c.DeletePattern(ctx, "session:*")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted, "* must also match '/'")
+
+	values, err := c.MGet(ctx, "sessions", "user:1")
+	require.NoError(t, err)
+	assert.Len(t, values, 2)
+
+	assert.True(t, memorycache.MatchPattern("user:[0-9]", "user:1"))
+	assert.False(t, memorycache.MatchPattern("user:[^0-9]", "user:1"))
+	assert.True(t, memorycache.MatchPattern(`a\*b`, "a*b"))
+	assert.False(t, memorycache.MatchPattern(`a\*b`, "axb"))
+}
@@ -0,0 +1,151 @@
+package memorycache
+
+import (
+	"context"
+	"time"
+)
+
+// SetWithTags stores a value and associates the key with the given tags.
+// The key is dropped from its tags when it is deleted, evicted, expires or is
+// overwritten, so a later write without a tag is not affected by InvalidateTag.
+func (c *MemoryCache) SetWithTags(ctx context.Context, key string, value string, expiration time.Duration, tags ...string) error {
+	return c.SetBytesWithTags(ctx, key, []byte(value), expiration, tags...)
+}
+
+// SetBytesWithTags stores a raw byte value and associates the key with the given tags.
+func (c *MemoryCache) SetBytesWithTags(ctx context.Context, key string, value []byte, expiration time.Duration, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.store(key, value, expiration, time.Now())
+	e.tags = append([]string(nil), tags...)
+	for _, tag := range e.tags {
+		keys, ok := c.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	return nil
+}
+
+// InvalidateTag deletes every key associated with any of the given tags.
+func (c *MemoryCache) InvalidateTag(ctx context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			c.remove(key)
+		}
+		delete(c.tags, tag)
+	}
+	return nil
+}
+
+// DeletePattern deletes all keys matching a Redis-style glob pattern
+// and returns the number of keys deleted.
+func (c *MemoryCache) DeletePattern(ctx context.Context, pattern string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var deleted int64
+	now := time.Now()
+	for key, elem := range c.items {
+		if !MatchPattern(pattern, key) {
+			continue
+		}
+		if !elem.Value.(*entry).expired(now) {
+			deleted++
+		}
+		c.remove(key)
+	}
+	return deleted, nil
+}
+
+// MatchPattern reports whether key matches a Redis-style glob pattern.
+// It supports * (any sequence), ? (any single byte), [abc], [^abc], [a-z]
+// and backslash escapes. Unlike path.Match, * also matches "/".
+func MatchPattern(pattern, key string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			// Collapse consecutive stars, then try every possible split.
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(key); i++ {
+				if MatchPattern(pattern, key[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(key) == 0 {
+				return false
+			}
+			pattern, key = pattern[1:], key[1:]
+		case '[':
+			if len(key) == 0 {
+				return false
+			}
+			rest, ok := matchClass(pattern[1:], key[0])
+			if !ok {
+				return false
+			}
+			pattern, key = rest, key[1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(key) == 0 || pattern[0] != key[0] {
+				return false
+			}
+			pattern, key = pattern[1:], key[1:]
+		}
+	}
+	return len(key) == 0
+}
+
+// matchClass matches b against a character class whose opening bracket has
+// already been consumed. It returns the pattern after the closing bracket.
+func matchClass(pattern string, b byte) (string, bool) {
+	negate := false
+	if len(pattern) > 0 && pattern[0] == '^' {
+		negate = true
+		pattern = pattern[1:]
+	}
+
+	matched := false
+	for len(pattern) > 0 && pattern[0] != ']' {
+		lo := pattern[0]
+		if lo == '\\' && len(pattern) > 1 {
+			pattern = pattern[1:]
+			lo = pattern[0]
+		}
+		pattern = pattern[1:]
+
+		hi := lo
+		if len(pattern) > 1 && pattern[0] == '-' && pattern[1] != ']' {
+			hi = pattern[1]
+			pattern = pattern[2:]
+		}
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if lo <= b && b <= hi {
+			matched = true
+		}
+	}
+	if len(pattern) == 0 {
+		// Unterminated class never matches, as in Redis.
+		return "", false
+	}
+	return pattern[1:], matched != negate
+}
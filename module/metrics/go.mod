@@ -0,0 +1,3 @@
+module github.com/things-kit/module/metrics
+
+go 1.21
@@ -0,0 +1,61 @@
+// Package metrics defines the framework's standard metrics interfaces.
+// Framework modules record counters, gauges and histograms through these
+// interfaces so that the metrics backend (Prometheus, OpenTelemetry, StatsD, etc.)
+// can be swapped without changing instrumented code.
+//
+// For a production-ready implementation, see the prometheus package.
+package metrics
+
+// Metrics creates named instruments. Instruments are identified by name;
+// requesting the same name twice returns an instrument backed by the same series.
+// Label values passed when recording must match labelNames in number and order.
+type Metrics interface {
+	// Counter returns a monotonically increasing counter.
+	Counter(name, help string, labelNames ...string) Counter
+
+	// Gauge returns a value that can go up and down.
+	Gauge(name, help string, labelNames ...string) Gauge
+
+	// Histogram returns a distribution of observed values.
+	// A nil buckets slice selects the implementation's default buckets.
+	Histogram(name, help string, buckets []float64, labelNames ...string) Histogram
+}
+
+// Counter is a monotonically increasing value.
+type Counter interface {
+	Inc(labelValues ...string)
+	Add(delta float64, labelValues ...string)
+}
+
+// Gauge is a value that can go up and down.
+type Gauge interface {
+	Set(value float64, labelValues ...string)
+	Add(delta float64, labelValues ...string)
+}
+
+// Histogram records observations in buckets.
+type Histogram interface {
+	Observe(value float64, labelValues ...string)
+}
+
+// Nop is a Metrics implementation that discards everything.
+// Modules use it when no Metrics implementation is provided.
+var Nop Metrics = nopMetrics{}
+
+type nopMetrics struct{}
+
+func (nopMetrics) Counter(string, string, ...string) Counter { return nopInstrument{} }
+
+func (nopMetrics) Gauge(string, string, ...string) Gauge { return nopInstrument{} }
+
+func (nopMetrics) Histogram(string, string, []float64, ...string) Histogram { return nopInstrument{} }
+
+type nopInstrument struct{}
+
+func (nopInstrument) Inc(...string) {}
+
+func (nopInstrument) Add(float64, ...string) {}
+
+func (nopInstrument) Set(float64, ...string) {}
+
+func (nopInstrument) Observe(float64, ...string) {}
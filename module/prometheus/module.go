@@ -0,0 +1,263 @@
+// Package prometheus provides the default Prometheus-based implementation of the
+// metrics.Metrics interface, and a lifecycle-managed HTTP endpoint for scraping.
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/viper"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/metrics"
+	"go.uber.org/fx"
+)
+
+// Module provides the Prometheus metrics module to the application.
+// It provides the metrics.Metrics interface and the *prometheus.Registry for power users,
+// and serves the registry on its own port.
+var Module = fx.Module("prometheus",
+	fx.Provide(
+		NewConfig,
+		NewRegistry,
+		NewPrometheusMetrics,
+		// Provide as metrics.Metrics interface
+		fx.Annotate(
+			func(m *PrometheusMetrics) metrics.Metrics { return m },
+			fx.As(new(metrics.Metrics)),
+		),
+	),
+	fx.Invoke(RunMetricsServer),
+)
+
+// Config holds the Prometheus metrics configuration.
+type Config struct {
+	Enabled   bool   `mapstructure:"enabled"`   // Serve the scrape endpoint
+	Host      string `mapstructure:"host"`      // Host to bind to (empty = all interfaces)
+	Port      int    `mapstructure:"port"`      // Port of the scrape endpoint
+	Path      string `mapstructure:"path"`      // Path of the scrape endpoint
+	Namespace string `mapstructure:"namespace"` // Prefix added to every metric name (e.g., "orders")
+}
+
+// NewConfig creates a new Prometheus configuration from Viper.
+func NewConfig(v *viper.Viper) *Config {
+	cfg := &Config{
+		Enabled: true,
+		Port:    9090,
+		Path:    "/metrics",
+	}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("metrics", cfg)
+	}
+
+	return cfg
+}
+
+// NewRegistry creates a Prometheus registry with the Go runtime and process collectors.
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// PrometheusMetrics implements the metrics.Metrics interface using a Prometheus registry.
+//
+// Requesting an instrument whose name is already taken by an instrument of a
+// different kind or with different labels is a programming error; it is logged
+// and a no-op instrument is returned so that the process keeps running.
+type PrometheusMetrics struct {
+	registry  *prometheus.Registry
+	namespace string
+	logger    log.Logger
+
+	mu         sync.Mutex
+	collectors map[string]registration
+}
+
+// registration is an instrument registered under a name.
+type registration struct {
+	collector  prometheus.Collector
+	kind       string
+	labelNames []string
+}
+
+// NewPrometheusMetrics creates a new Prometheus-backed metrics implementation.
+func NewPrometheusMetrics(registry *prometheus.Registry, cfg *Config, logger log.Logger) *PrometheusMetrics {
+	return &PrometheusMetrics{
+		registry:   registry,
+		namespace:  cfg.Namespace,
+		logger:     logger,
+		collectors: make(map[string]registration),
+	}
+}
+
+// Counter returns a counter vector registered under name.
+func (m *PrometheusMetrics) Counter(name, help string, labelNames ...string) metrics.Counter {
+	c := m.register("counter", name, labelNames, func() prometheus.Collector {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.namespace,
+			Name:      name,
+			Help:      help,
+		}, labelNames)
+	})
+	vec, ok := c.(*prometheus.CounterVec)
+	if !ok {
+		return metrics.Nop.Counter(name, help, labelNames...)
+	}
+	return counter{vec: vec}
+}
+
+// Gauge returns a gauge vector registered under name.
+func (m *PrometheusMetrics) Gauge(name, help string, labelNames ...string) metrics.Gauge {
+	c := m.register("gauge", name, labelNames, func() prometheus.Collector {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: m.namespace,
+			Name:      name,
+			Help:      help,
+		}, labelNames)
+	})
+	vec, ok := c.(*prometheus.GaugeVec)
+	if !ok {
+		return metrics.Nop.Gauge(name, help, labelNames...)
+	}
+	return gauge{vec: vec}
+}
+
+// Histogram returns a histogram vector registered under name.
+func (m *PrometheusMetrics) Histogram(name, help string, buckets []float64, labelNames ...string) metrics.Histogram {
+	c := m.register("histogram", name, labelNames, func() prometheus.Collector {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: m.namespace,
+			Name:      name,
+			Help:      help,
+			Buckets:   buckets,
+		}, labelNames)
+	})
+	vec, ok := c.(*prometheus.HistogramVec)
+	if !ok {
+		return metrics.Nop.Histogram(name, help, buckets, labelNames...)
+	}
+	return histogram{vec: vec}
+}
+
+// register returns the collector already registered under name, or creates and
+// registers a new one. It returns nil, after logging the conflict, if name is
+// already used by an instrument of another kind or with other labels.
+func (m *PrometheusMetrics) register(kind, name string, labelNames []string, create func() prometheus.Collector) prometheus.Collector {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r, ok := m.collectors[name]; ok {
+		if r.kind != kind || !slices.Equal(r.labelNames, labelNames) {
+			m.logger.Error("Metric already registered with a different kind or labels, using a no-op instrument",
+				fmt.Errorf("metric %s is a %s with labels %v", name, r.kind, r.labelNames),
+				log.Field{Key: "metric", Value: name},
+				log.Field{Key: "kind", Value: kind},
+				log.Field{Key: "labels", Value: labelNames},
+			)
+			return nil
+		}
+		return r.collector
+	}
+
+	c := create()
+	if err := m.registry.Register(c); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if !errors.As(err, &already) {
+			m.logger.Error("Failed to register metric, using a no-op instrument", err,
+				log.Field{Key: "metric", Value: name},
+				log.Field{Key: "kind", Value: kind},
+			)
+			return nil
+		}
+		if reflect.TypeOf(already.ExistingCollector) != reflect.TypeOf(c) {
+			m.logger.Error("Metric already registered as another collector type, using a no-op instrument", err,
+				log.Field{Key: "metric", Value: name},
+				log.Field{Key: "kind", Value: kind},
+			)
+			return nil
+		}
+		c = already.ExistingCollector
+	}
+	m.collectors[name] = registration{collector: c, kind: kind, labelNames: slices.Clone(labelNames)}
+	return c
+}
+
+// counter adapts a CounterVec to metrics.Counter.
+type counter struct{ vec *prometheus.CounterVec }
+
+func (c counter) Inc(labelValues ...string) { c.vec.WithLabelValues(labelValues...).Inc() }
+
+func (c counter) Add(delta float64, labelValues ...string) {
+	c.vec.WithLabelValues(labelValues...).Add(delta)
+}
+
+// gauge adapts a GaugeVec to metrics.Gauge.
+type gauge struct{ vec *prometheus.GaugeVec }
+
+func (g gauge) Set(value float64, labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Set(value)
+}
+
+func (g gauge) Add(delta float64, labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Add(delta)
+}
+
+// histogram adapts a HistogramVec to metrics.Histogram.
+type histogram struct{ vec *prometheus.HistogramVec }
+
+func (h histogram) Observe(value float64, labelValues ...string) {
+	h.vec.WithLabelValues(labelValues...).Observe(value)
+}
+
+// MetricsServerParams contains all dependencies needed to run the scrape endpoint.
+type MetricsServerParams struct {
+	fx.In
+	Lifecycle fx.Lifecycle
+	Logger    log.Logger
+	Config    *Config
+	Registry  *prometheus.Registry
+}
+
+// RunMetricsServer serves the registry on the configured port.
+func RunMetricsServer(p MetricsServerParams) {
+	if !p.Config.Enabled {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(p.Config.Path, promhttp.HandlerFor(p.Registry, promhttp.HandlerOpts{}))
+
+	addr := fmt.Sprintf("%s:%d", p.Config.Host, p.Config.Port)
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			p.Logger.Info("Starting metrics server", log.Field{Key: "address", Value: addr}, log.Field{Key: "path", Value: p.Config.Path})
+
+			go func() {
+				if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					p.Logger.Error("Metrics server error", err, log.Field{Key: "address", Value: addr})
+				}
+			}()
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			p.Logger.Info("Stopping metrics server", log.Field{Key: "address", Value: addr})
+			return server.Shutdown(ctx)
+		},
+	})
+}
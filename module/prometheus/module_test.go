@@ -0,0 +1,143 @@
+package prometheus_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/prometheus"
+)
+
+// errorLogger records the messages logged at error level
+type errorLogger struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (l *errorLogger) Info(string, ...log.Field)  {}
+func (l *errorLogger) Debug(string, ...log.Field) {}
+func (l *errorLogger) Warn(string, ...log.Field)  {}
+func (l *errorLogger) Error(msg string, _ error, _ ...log.Field) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, msg)
+}
+func (l *errorLogger) InfoC(context.Context, string, ...log.Field)        {}
+func (l *errorLogger) DebugC(context.Context, string, ...log.Field)       {}
+func (l *errorLogger) WarnC(context.Context, string, error, ...log.Field) {}
+func (l *errorLogger) ErrorC(_ context.Context, msg string, err error, fields ...log.Field) {
+	l.Error(msg, err, fields...)
+}
+
+// newMetrics returns metrics backed by an empty registry
+func newMetrics() (*prometheus.PrometheusMetrics, *promclient.Registry, *errorLogger) {
+	registry := promclient.NewRegistry()
+	logger := &errorLogger{}
+	return prometheus.NewPrometheusMetrics(registry, &prometheus.Config{Namespace: "orders"}, logger), registry, logger
+}
+
+// counterValue returns the value of the first series of the named counter
+func counterValue(t *testing.T, registry *promclient.Registry, name string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, f := range families {
+		if f.GetName() == name {
+			return f.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	t.Fatalf("metric %s not registered", name)
+	return 0
+}
+
+// TestInstruments verifies that each kind of instrument is registered and records values
+func TestInstruments(t *testing.T) {
+	m, registry, logger := newMetrics()
+
+	m.Counter("requests_total", "Requests.", "method").Add(2, "GET")
+	m.Gauge("in_flight", "In-flight requests.").Set(3)
+	m.Histogram("latency_seconds", "Latency.", []float64{0.1, 1}, "method").Observe(0.5, "GET")
+
+	assert.Equal(t, 3, testutil.CollectAndCount(registry))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := map[string]float64{}
+	for _, f := range families {
+		metric := f.GetMetric()[0]
+		switch {
+		case metric.Counter != nil:
+			values[f.GetName()] = metric.Counter.GetValue()
+		case metric.Gauge != nil:
+			values[f.GetName()] = metric.Gauge.GetValue()
+		case metric.Histogram != nil:
+			values[f.GetName()] = metric.Histogram.GetSampleSum()
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		"orders_requests_total":  2,
+		"orders_in_flight":       3,
+		"orders_latency_seconds": 0.5,
+	}, values)
+	assert.Empty(t, logger.errors)
+}
+
+// TestReusedNames verifies that a reused name shares its series and a conflicting reuse degrades to a no-op
+func TestReusedNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		reuse    func(m *prometheus.PrometheusMetrics)
+		want     float64
+		conflict bool
+	}{
+		{
+			name:  "same kind and labels",
+			reuse: func(m *prometheus.PrometheusMetrics) { m.Counter("jobs_total", "Jobs.", "queue").Inc("default") },
+			want:  2,
+		},
+		{
+			name:     "different kind",
+			reuse:    func(m *prometheus.PrometheusMetrics) { m.Gauge("jobs_total", "Jobs.", "queue").Set(10, "default") },
+			want:     1,
+			conflict: true,
+		},
+		{
+			name: "different labels",
+			reuse: func(m *prometheus.PrometheusMetrics) {
+				m.Counter("jobs_total", "Jobs.", "queue", "priority").Inc("default")
+			},
+			want:     1,
+			conflict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, registry, logger := newMetrics()
+			m.Counter("jobs_total", "Jobs.", "queue").Inc("default")
+
+			assert.NotPanics(t, func() { tt.reuse(m) })
+			assert.Equal(t, tt.want, counterValue(t, registry, "orders_jobs_total"))
+			assert.Equal(t, 1, testutil.CollectAndCount(registry))
+			assert.Equal(t, tt.conflict, len(logger.errors) > 0)
+		})
+	}
+}
+
+// TestExistingRegistryCollector verifies that a name already taken on the registry by another collector type is not fatal
+func TestExistingRegistryCollector(t *testing.T) {
+	m, registry, logger := newMetrics()
+	require.NoError(t, registry.Register(promclient.NewGaugeVec(promclient.GaugeOpts{
+		Namespace: "orders",
+		Name:      "jobs_total",
+		Help:      "Jobs.",
+	}, []string{"queue"})))
+
+	assert.NotPanics(t, func() { m.Counter("jobs_total", "Jobs.", "queue").Inc("default") })
+	assert.Len(t, logger.errors, 1)
+}
@@ -0,0 +1,94 @@
+// Package ratelimit defines the framework's standard rate limiting interface.
+// By depending on this interface rather than a concrete backend, rate limits can
+// be shared across replicas in production (redis) and kept in-process in tests
+// and single-instance deployments (memory).
+//
+// For a production-ready implementation, see the redis package.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Algorithm selects how requests are counted against a Limit.
+type Algorithm string
+
+const (
+	// TokenBucket refills Rate tokens every Period up to Burst, and each request
+	// takes one token. It allows short bursts while enforcing the average rate.
+	TokenBucket Algorithm = "token_bucket"
+
+	// SlidingWindow allows at most Rate requests in any Period, approximating the
+	// sliding window by weighting the previous fixed window's count.
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// ErrInvalidLimit is returned when a Limit cannot be enforced.
+var ErrInvalidLimit = errors.New("ratelimit: invalid limit")
+
+// Limit describes how many requests a key may make.
+// It can be loaded from configuration with viper's UnmarshalKey.
+type Limit struct {
+	Algorithm Algorithm     `mapstructure:"algorithm"` // Default: token_bucket
+	Rate      int           `mapstructure:"rate"`      // Requests allowed per Period
+	Period    time.Duration `mapstructure:"period"`    // Default: 1s
+	Burst     int           `mapstructure:"burst"`     // Token bucket capacity (default: Rate)
+}
+
+// PerSecond returns a token bucket limit of rate requests per second.
+func PerSecond(rate int) Limit {
+	return Limit{Algorithm: TokenBucket, Rate: rate, Period: time.Second}
+}
+
+// PerMinute returns a token bucket limit of rate requests per minute.
+func PerMinute(rate int) Limit {
+	return Limit{Algorithm: TokenBucket, Rate: rate, Period: time.Minute}
+}
+
+// Normalize returns the limit with defaults applied, or ErrInvalidLimit.
+// Limiter implementations call it before enforcing a limit.
+func (l Limit) Normalize() (Limit, error) {
+	if l.Algorithm == "" {
+		l.Algorithm = TokenBucket
+	}
+	if l.Period == 0 {
+		l.Period = time.Second
+	}
+	if l.Burst == 0 {
+		l.Burst = l.Rate
+	}
+
+	switch {
+	case l.Algorithm != TokenBucket && l.Algorithm != SlidingWindow:
+		return l, fmt.Errorf("%w: unknown algorithm %q", ErrInvalidLimit, l.Algorithm)
+	case l.Rate <= 0:
+		return l, fmt.Errorf("%w: rate must be positive", ErrInvalidLimit)
+	case l.Period < time.Millisecond:
+		return l, fmt.Errorf("%w: period must be at least 1ms", ErrInvalidLimit)
+	case l.Burst < 0:
+		return l, fmt.Errorf("%w: burst must not be negative", ErrInvalidLimit)
+	}
+	return l, nil
+}
+
+// Result reports the outcome of a rate limit check.
+type Result struct {
+	Allowed    bool          // Whether the request may proceed
+	Limit      int           // Maximum requests (Burst for token buckets, Rate for windows)
+	Remaining  int           // Requests still allowed right now
+	RetryAfter time.Duration // When denied, how long until a request would be allowed
+	ResetAfter time.Duration // How long until the key is back to its full allowance
+}
+
+// Limiter checks requests against rate limits.
+// Keys are opaque; callers namespace them (e.g. "api:ip:203.0.113.7") so that
+// different limits never share state.
+type Limiter interface {
+	// Allow records one request for key and reports whether it is within limit.
+	// An error means the limit could not be checked; callers decide whether to
+	// fail open or closed.
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
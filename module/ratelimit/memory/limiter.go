@@ -0,0 +1,169 @@
+// Package memory provides an in-process implementation of the ratelimit.Limiter
+// interface for tests and single-instance applications. Limits are not shared
+// between replicas; use the redis module's limiter for that.
+package memory
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/things-kit/module/ratelimit"
+	"go.uber.org/fx"
+)
+
+// Module provides an in-memory ratelimit.Limiter to the application.
+var Module = fx.Module("ratelimit.memory",
+	fx.Provide(
+		NewLimiter,
+		fx.Annotate(
+			func(l *Limiter) ratelimit.Limiter { return l },
+			fx.As(new(ratelimit.Limiter)),
+		),
+	),
+)
+
+// sweepInterval is how often idle keys are removed.
+const sweepInterval = time.Minute
+
+// state holds the counters of one key. Only the fields of the key's algorithm are used.
+type state struct {
+	// Token bucket
+	tokens float64
+	last   time.Time
+
+	// Sliding window
+	window int64
+	cur    int
+	prev   int
+
+	expiresAt time.Time // After this the key is back to its full allowance
+}
+
+// Limiter implements ratelimit.Limiter using a process-local map.
+// It uses the same algorithms as the Redis implementation.
+type Limiter struct {
+	mu        sync.Mutex
+	keys      map[string]*state
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+// NewLimiter creates a new in-memory limiter.
+func NewLimiter() *Limiter {
+	return &Limiter{
+		keys: make(map[string]*state),
+		now:  time.Now,
+	}
+}
+
+// Allow records one request for key and reports whether it is within limit.
+func (l *Limiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	limit, err := limit.Normalize()
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	s, ok := l.keys[key]
+	if !ok {
+		s = &state{}
+		l.keys[key] = s
+	}
+
+	if limit.Algorithm == ratelimit.SlidingWindow {
+		return slidingWindow(s, limit, now), nil
+	}
+	return tokenBucket(s, limit, now), nil
+}
+
+// sweep removes keys that are back to their full allowance.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+	for key, s := range l.keys {
+		if !now.Before(s.expiresAt) {
+			delete(l.keys, key)
+		}
+	}
+	l.nextSweep = now.Add(sweepInterval)
+}
+
+// tokenBucket refills the bucket for the time elapsed since the last request
+// and takes one token if available.
+func tokenBucket(s *state, limit ratelimit.Limit, now time.Time) ratelimit.Result {
+	burst := float64(limit.Burst)
+	perNanosecond := float64(limit.Rate) / float64(limit.Period)
+
+	if s.last.IsZero() {
+		s.tokens = burst
+		s.last = now
+	}
+	s.tokens = math.Min(burst, s.tokens+float64(now.Sub(s.last))*perNanosecond)
+	s.last = now
+
+	result := ratelimit.Result{Limit: limit.Burst}
+	if s.tokens >= 1 {
+		s.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - s.tokens) / perNanosecond))
+	}
+	result.Remaining = int(s.tokens)
+	result.ResetAfter = time.Duration(math.Ceil((burst - s.tokens) / perNanosecond))
+	s.expiresAt = now.Add(result.ResetAfter)
+	return result
+}
+
+// slidingWindow counts requests in fixed windows of one period and estimates
+// the sliding count as the current window plus the overlapping share of the
+// previous one.
+func slidingWindow(s *state, limit ratelimit.Limit, now time.Time) ratelimit.Result {
+	period := int64(limit.Period)
+	index := now.UnixNano() / period
+
+	switch s.window {
+	case index:
+	case index - 1:
+		s.prev, s.cur = s.cur, 0
+	default:
+		s.prev, s.cur = 0, 0
+	}
+	s.window = index
+
+	elapsed := now.UnixNano() - index*period
+	remainingInWindow := period - elapsed
+	count := float64(s.prev)*float64(remainingInWindow)/float64(period) + float64(s.cur)
+	rate := float64(limit.Rate)
+
+	result := ratelimit.Result{Limit: limit.Rate}
+	if count+1 <= rate {
+		s.cur++
+		count++
+		result.Allowed = true
+	} else if s.cur+1 > limit.Rate {
+		// The current window alone is full; wait for it to decay in the next one.
+		result.RetryAfter = time.Duration(remainingInWindow) +
+			time.Duration(float64(period)*(1-(rate-1)/float64(s.cur)))
+	} else {
+		// Wait for the previous window's share to decay enough.
+		result.RetryAfter = time.Duration(float64(remainingInWindow) -
+			(rate-float64(s.cur)-1)*float64(period)/float64(s.prev))
+	}
+	result.Remaining = int(math.Max(0, math.Floor(rate-count)))
+
+	if s.cur > 0 {
+		result.ResetAfter = time.Duration(remainingInWindow + period)
+	} else {
+		result.ResetAfter = time.Duration(remainingInWindow)
+	}
+	s.expiresAt = now.Add(result.ResetAfter)
+	return result
+}
@@ -0,0 +1,54 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/ratelimit"
+	"github.com/things-kit/module/ratelimit/memory"
+)
+
+// TestLimiter verifies that both algorithms deny requests beyond the limit
+func TestLimiter(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit ratelimit.Limit
+	}{
+		{name: "token bucket", limit: ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Rate: 3, Period: time.Hour}},
+		{name: "sliding window", limit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Rate: 3, Period: time.Hour}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			limiter := memory.NewLimiter()
+
+			for i := 0; i < 3; i++ {
+				result, err := limiter.Allow(ctx, "client", tt.limit)
+				require.NoError(t, err)
+				assert.True(t, result.Allowed)
+				assert.Equal(t, 2-i, result.Remaining)
+			}
+
+			result, err := limiter.Allow(ctx, "client", tt.limit)
+			require.NoError(t, err)
+			assert.False(t, result.Allowed)
+			assert.Equal(t, 0, result.Remaining)
+			assert.Greater(t, result.RetryAfter, time.Duration(0))
+
+			result, err = limiter.Allow(ctx, "other", tt.limit)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed, "keys must be limited independently")
+		})
+	}
+}
+
+// TestLimiterInvalidLimit verifies that unusable limits are rejected
+func TestLimiterInvalidLimit(t *testing.T) {
+	_, err := memory.NewLimiter().Allow(context.Background(), "client", ratelimit.Limit{})
+	assert.True(t, errors.Is(err, ratelimit.ErrInvalidLimit))
+}
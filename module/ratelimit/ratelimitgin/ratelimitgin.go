@@ -0,0 +1,99 @@
+// Package ratelimitgin applies ratelimit limits to the requests of the httpgin module.
+package ratelimitgin
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/ratelimit"
+)
+
+// KeyFunc extracts the client key a request is limited by.
+// Returning false exempts the request from the limit.
+type KeyFunc func(c *gin.Context) (string, bool)
+
+// ByIP limits requests by client IP, as resolved by gin's ClientIP.
+func ByIP() KeyFunc {
+	return func(c *gin.Context) (string, bool) {
+		return "ip:" + c.ClientIP(), true
+	}
+}
+
+// ByHeader limits requests by the value of a header such as X-API-Key.
+// Requests without the header are not limited.
+func ByHeader(name string) KeyFunc {
+	return func(c *gin.Context) (string, bool) {
+		value := c.GetHeader(name)
+		return "header:" + value, value != ""
+	}
+}
+
+// ByPrincipal limits requests by the authenticated principal that
+// principal finds in the request context. Unauthenticated requests are not limited,
+// so combine it with an IP limit on public routes.
+func ByPrincipal(principal func(ctx context.Context) (string, bool)) KeyFunc {
+	return func(c *gin.Context) (string, bool) {
+		id, ok := principal(c.Request.Context())
+		return "principal:" + id, ok
+	}
+}
+
+// RateLimit returns middleware that enforces limit per client key.
+// The name namespaces the keys so that different limits never share state.
+// Every limited response carries X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset headers; denied requests get 429 with Retry-After.
+// If the limiter fails, the error is logged and the request is allowed. An
+// invalid limit is reported when the middleware is built.
+//
+// Example:
+//
+//	httpgin.AsGinMiddleware(func(limiter ratelimit.Limiter, logger log.Logger) (gin.HandlerFunc, error) {
+//	    return ratelimitgin.RateLimit(limiter, logger, "api", ratelimit.PerMinute(600), ratelimitgin.ByIP())
+//	}, httpgin.OrderDefault)
+func RateLimit(limiter ratelimit.Limiter, logger log.Logger, name string, limit ratelimit.Limit, key KeyFunc) (gin.HandlerFunc, error) {
+	limit, err := limit.Normalize()
+	if err != nil {
+		return nil, fmt.Errorf("rate limit %q: %w", name, err)
+	}
+
+	return func(c *gin.Context) {
+		k, ok := key(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		result, err := limiter.Allow(c.Request.Context(), name+":"+k, limit)
+		if err != nil {
+			logger.ErrorC(c.Request.Context(), "Rate limit check failed, allowing request", err,
+				log.Field{Key: "limit", Value: name},
+				log.Field{Key: "path", Value: c.FullPath()},
+			)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", seconds(result.ResetAfter))
+
+		if !result.Allowed {
+			c.Header("Retry-After", seconds(result.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+
+		c.Next()
+	}, nil
+}
+
+// seconds formats d as whole seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,100 @@
+package ratelimitgin_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/ratelimit"
+	"github.com/things-kit/module/ratelimit/memory"
+	"github.com/things-kit/module/ratelimit/ratelimitgin"
+)
+
+// errorLogger records the errors it is given
+type errorLogger struct{ errs []error }
+
+func (*errorLogger) Info(string, ...log.Field)                   {}
+func (l *errorLogger) Error(_ string, err error, _ ...log.Field) { l.errs = append(l.errs, err) }
+func (*errorLogger) Debug(string, ...log.Field)                  {}
+func (*errorLogger) Warn(string, ...log.Field)                   {}
+func (*errorLogger) InfoC(context.Context, string, ...log.Field) {}
+func (l *errorLogger) ErrorC(_ context.Context, _ string, err error, _ ...log.Field) {
+	l.errs = append(l.errs, err)
+}
+func (*errorLogger) DebugC(context.Context, string, ...log.Field)       {}
+func (*errorLogger) WarnC(context.Context, string, error, ...log.Field) {}
+
+// failingLimiter fails every check
+type failingLimiter struct{ err error }
+
+func (l failingLimiter) Allow(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, l.err
+}
+
+func newEngine(t *testing.T, limiter ratelimit.Limiter, logger log.Logger) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	middleware, err := ratelimitgin.RateLimit(limiter, logger, "api", ratelimit.PerMinute(1), ratelimitgin.ByHeader("X-API-Key"))
+	require.NoError(t, err)
+
+	engine := gin.New()
+	engine.Use(middleware)
+	engine.GET("/", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	return engine
+}
+
+func get(engine *gin.Engine, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+// TestRateLimit verifies headers, denial, per-key state and exempt requests
+func TestRateLimit(t *testing.T) {
+	engine := newEngine(t, memory.NewLimiter(), &errorLogger{})
+
+	w := get(engine, "a")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = get(engine, "a")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, get(engine, "b").Code, "other keys have their own limit")
+
+	for range 3 {
+		w = get(engine, "")
+		assert.Equal(t, http.StatusOK, w.Code, "requests without the header are not limited")
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+// TestInvalidLimit verifies that an invalid limit fails when the middleware is built
+func TestInvalidLimit(t *testing.T) {
+	_, err := ratelimitgin.RateLimit(memory.NewLimiter(), &errorLogger{}, "api", ratelimit.Limit{Rate: -1}, ratelimitgin.ByIP())
+	assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+}
+
+// TestLimiterError verifies that limiter errors are logged and the request is allowed
+func TestLimiterError(t *testing.T) {
+	errDown := errors.New("redis down")
+	logger := &errorLogger{}
+	engine := newEngine(t, failingLimiter{err: errDown}, logger)
+
+	w := get(engine, "a")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", w.Body.String())
+	assert.Equal(t, []error{errDown}, logger.errs)
+}
@@ -0,0 +1,134 @@
+// Package ratelimitgrpc applies ratelimit limits to the calls of the grpc module.
+package ratelimitgrpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// KeyFunc extracts the client key a call is limited by.
+// Returning false exempts the call from the limit.
+type KeyFunc func(ctx context.Context, fullMethod string) (string, bool)
+
+// ByPeer limits calls by the client's IP address.
+func ByPeer() KeyFunc {
+	return func(ctx context.Context, _ string) (string, bool) {
+		p, ok := peer.FromContext(ctx)
+		if !ok || p.Addr == nil {
+			return "", false
+		}
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "ip:" + host, true
+	}
+}
+
+// ByMetadata limits calls by the value of an incoming metadata key
+// such as "x-api-key". Calls without the key are not limited.
+func ByMetadata(key string) KeyFunc {
+	return func(ctx context.Context, _ string) (string, bool) {
+		values := metadata.ValueFromIncomingContext(ctx, key)
+		if len(values) == 0 || values[0] == "" {
+			return "", false
+		}
+		return "metadata:" + values[0], true
+	}
+}
+
+// ByPrincipal limits calls by the authenticated principal that
+// principal finds in the context. Unauthenticated calls are not limited.
+func ByPrincipal(principal func(ctx context.Context) (string, bool)) KeyFunc {
+	return func(ctx context.Context, _ string) (string, bool) {
+		id, ok := principal(ctx)
+		return "principal:" + id, ok
+	}
+}
+
+// UnaryServerInterceptor returns a unary interceptor that enforces limit per client key.
+// The name namespaces the keys so that different limits never share state.
+// Denied calls fail with codes.ResourceExhausted and a "retry-after" header in
+// seconds. If the limiter fails, the error is logged and the call is allowed.
+// An invalid limit is reported when the interceptor is built.
+//
+// Example:
+//
+//	grpcmodule.AsUnaryInterceptor(func(limiter ratelimit.Limiter, logger log.Logger) (grpc.UnaryServerInterceptor, error) {
+//	    return ratelimitgrpc.UnaryServerInterceptor(limiter, logger, "api", ratelimit.PerSecond(50), ratelimitgrpc.ByPeer())
+//	}, grpcmodule.OrderDefault)
+func UnaryServerInterceptor(limiter ratelimit.Limiter, logger log.Logger, name string, limit ratelimit.Limit, key KeyFunc) (grpc.UnaryServerInterceptor, error) {
+	check, err := newCheck(limiter, logger, name, limit, key)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}, nil
+}
+
+// StreamServerInterceptor returns a stream interceptor that enforces limit per
+// client key when a stream is opened. Messages within a stream are not limited.
+func StreamServerInterceptor(limiter ratelimit.Limiter, logger log.Logger, name string, limit ratelimit.Limit, key KeyFunc) (grpc.StreamServerInterceptor, error) {
+	check, err := newCheck(limiter, logger, name, limit, key)
+	if err != nil {
+		return nil, err
+	}
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := check(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}, nil
+}
+
+// newCheck validates limit and returns a function failing calls that
+// exceed it with a ResourceExhausted status.
+func newCheck(limiter ratelimit.Limiter, logger log.Logger, name string, limit ratelimit.Limit, key KeyFunc) (func(ctx context.Context, fullMethod string) error, error) {
+	limit, err := limit.Normalize()
+	if err != nil {
+		return nil, fmt.Errorf("rate limit %q: %w", name, err)
+	}
+
+	return func(ctx context.Context, fullMethod string) error {
+		k, ok := key(ctx, fullMethod)
+		if !ok {
+			return nil
+		}
+
+		result, err := limiter.Allow(ctx, name+":"+k, limit)
+		if err != nil {
+			logger.ErrorC(ctx, "Rate limit check failed, allowing call", err,
+				log.Field{Key: "limit", Value: name},
+				log.Field{Key: "method", Value: fullMethod},
+			)
+			return nil
+		}
+		if result.Allowed {
+			return nil
+		}
+
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", seconds(result.RetryAfter)))
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry after %s", result.RetryAfter.Round(time.Millisecond))
+	}, nil
+}
+
+// seconds formats d as whole seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
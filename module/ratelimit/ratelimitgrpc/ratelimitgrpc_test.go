@@ -0,0 +1,117 @@
+package ratelimitgrpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/ratelimit"
+	"github.com/things-kit/module/ratelimit/memory"
+	"github.com/things-kit/module/ratelimit/ratelimitgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// errorLogger records the errors it is given
+type errorLogger struct{ errs []error }
+
+func (*errorLogger) Info(string, ...log.Field)                   {}
+func (l *errorLogger) Error(_ string, err error, _ ...log.Field) { l.errs = append(l.errs, err) }
+func (*errorLogger) Debug(string, ...log.Field)                  {}
+func (*errorLogger) Warn(string, ...log.Field)                   {}
+func (*errorLogger) InfoC(context.Context, string, ...log.Field) {}
+func (l *errorLogger) ErrorC(_ context.Context, _ string, err error, _ ...log.Field) {
+	l.errs = append(l.errs, err)
+}
+func (*errorLogger) DebugC(context.Context, string, ...log.Field)       {}
+func (*errorLogger) WarnC(context.Context, string, error, ...log.Field) {}
+
+// failingLimiter fails every check
+type failingLimiter struct{ err error }
+
+func (l failingLimiter) Allow(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, l.err
+}
+
+// serverStream is a grpc.ServerStream carrying only a context
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s serverStream) Context() context.Context { return s.ctx }
+
+func withAPIKey(key string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", key))
+}
+
+// TestUnaryServerInterceptor verifies denial, per-key state and exempt calls
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor, err := ratelimitgrpc.UnaryServerInterceptor(memory.NewLimiter(), &errorLogger{}, "api",
+		ratelimit.PerMinute(1), ratelimitgrpc.ByMetadata("x-api-key"))
+	require.NoError(t, err)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+
+	resp, err := interceptor(withAPIKey("a"), nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(withAPIKey("a"), nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = interceptor(withAPIKey("b"), nil, info, handler)
+	assert.NoError(t, err, "other keys have their own limit")
+
+	for range 3 {
+		_, err = interceptor(context.Background(), nil, info, handler)
+		assert.NoError(t, err, "calls without the key are not limited")
+	}
+}
+
+// TestStreamServerInterceptor verifies that opening streams is limited
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor, err := ratelimitgrpc.StreamServerInterceptor(memory.NewLimiter(), &errorLogger{}, "api",
+		ratelimit.PerMinute(1), ratelimitgrpc.ByMetadata("x-api-key"))
+	require.NoError(t, err)
+
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+	handler := func(any, grpc.ServerStream) error { return nil }
+	ss := serverStream{ctx: withAPIKey("a")}
+
+	require.NoError(t, interceptor(nil, ss, info, handler))
+	err = interceptor(nil, ss, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+// TestInvalidLimit verifies that an invalid limit fails when the interceptors are built
+func TestInvalidLimit(t *testing.T) {
+	limit := ratelimit.Limit{Algorithm: "leaky_bucket", Rate: 10}
+
+	_, err := ratelimitgrpc.UnaryServerInterceptor(memory.NewLimiter(), &errorLogger{}, "api", limit, ratelimitgrpc.ByPeer())
+	assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+
+	_, err = ratelimitgrpc.StreamServerInterceptor(memory.NewLimiter(), &errorLogger{}, "api", ratelimit.Limit{}, ratelimitgrpc.ByPeer())
+	assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+}
+
+// TestLimiterError verifies that limiter errors are logged and the call is allowed
+func TestLimiterError(t *testing.T) {
+	errDown := errors.New("redis down")
+	logger := &errorLogger{}
+	interceptor, err := ratelimitgrpc.UnaryServerInterceptor(failingLimiter{err: errDown}, logger, "api",
+		ratelimit.PerSecond(1), ratelimitgrpc.ByMetadata("x-api-key"))
+	require.NoError(t, err)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	resp, err := interceptor(withAPIKey("a"), nil, info, func(context.Context, any) (any, error) { return "ok", nil })
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	assert.Equal(t, []error{errDown}, logger.errs)
+}
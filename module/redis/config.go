@@ -0,0 +1,203 @@
+package redis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+// Deployment modes supported by the module.
+const (
+	ModeStandalone = "standalone" // Single Redis server
+	ModeSentinel   = "sentinel"   // Sentinel-managed master/replica set
+	ModeCluster    = "cluster"    // Redis Cluster
+)
+
+// Config holds the Redis configuration.
+//
+// In standalone mode the connection can be described by URL, or by Addrs plus
+// the individual fields below. Explicit fields take precedence over values
+// parsed from URL. Sentinel and cluster modes require Addrs.
+type Config struct {
+	Mode string `mapstructure:"mode"` // standalone, sentinel, cluster
+
+	URL        string   `mapstructure:"url"`         // Redis URL (e.g., redis://localhost:6379/0), standalone only
+	Addrs      []string `mapstructure:"addrs"`       // Server, sentinel, or cluster seed addresses (host:port)
+	MasterName string   `mapstructure:"master_name"` // Sentinel master name
+	Username   string   `mapstructure:"username"`
+	Password   string   `mapstructure:"password"`
+	DB         int      `mapstructure:"db"` // Database index (standalone and sentinel only)
+
+	SentinelUsername string `mapstructure:"sentinel_username"`
+	SentinelPassword string `mapstructure:"sentinel_password"`
+
+	// Connection pool settings (0 = go-redis default)
+	PoolSize        int           `mapstructure:"pool_size"`
+	MinIdleConns    int           `mapstructure:"min_idle_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	PoolTimeout     time.Duration `mapstructure:"pool_timeout"`
+
+	// Timeouts and retries (0 = go-redis default)
+	DialTimeout  time.Duration `mapstructure:"dial_timeout"`
+	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	MaxRetries   int           `mapstructure:"max_retries"`
+
+	// Cluster-only settings
+	ReadOnly       bool `mapstructure:"read_only"`        // Allow read commands on replicas
+	RouteByLatency bool `mapstructure:"route_by_latency"` // Route read commands to the closest node
+
+	TLS TLSConfig `mapstructure:"tls"`
+
+	KeyPrefix         string        `mapstructure:"key_prefix"`          // Prepended to every cache and lock key (e.g., "orders:")
+	LockRetryInterval time.Duration `mapstructure:"lock_retry_interval"` // Polling interval for Locker.TryAcquire
+
+	Instrumentation InstrumentationConfig `mapstructure:"instrumentation"`
+}
+
+// InstrumentationConfig holds the settings for cache metrics and slow-operation logging.
+type InstrumentationConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	SlowThreshold time.Duration `mapstructure:"slow_threshold"` // Operations slower than this are logged (0 = never)
+	HashKeys      bool          `mapstructure:"hash_keys"`      // Log a SHA-256 of the key instead of the key itself
+}
+
+// TLSConfig holds the TLS settings for Redis connections.
+type TLSConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CAFile             string `mapstructure:"ca_file"`              // PEM bundle used to verify the server
+	CertFile           string `mapstructure:"cert_file"`            // Client certificate for mutual TLS
+	KeyFile            string `mapstructure:"key_file"`             // Client private key for mutual TLS
+	ServerName         string `mapstructure:"server_name"`          // Overrides the name used to verify the server certificate
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"` // Disables server verification (testing only)
+}
+
+// NewConfig creates a new Redis configuration from Viper.
+func NewConfig(v *viper.Viper) *Config {
+	cfg := &Config{
+		Mode:              ModeStandalone,
+		URL:               "redis://localhost:6379/0", // Default URL
+		LockRetryInterval: 100 * time.Millisecond,
+		Instrumentation: InstrumentationConfig{
+			SlowThreshold: 100 * time.Millisecond,
+		},
+	}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("redis", cfg)
+	}
+
+	return cfg
+}
+
+// UniversalOptions converts the configuration into go-redis options.
+// It validates that the settings required by the selected mode are present.
+func (c *Config) UniversalOptions() (*redis.UniversalOptions, error) {
+	opts := &redis.UniversalOptions{}
+
+	switch c.Mode {
+	case ModeStandalone, "":
+		if len(c.Addrs) == 0 && c.URL != "" {
+			parsed, err := redis.ParseURL(c.URL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+			}
+			opts.Addrs = []string{parsed.Addr}
+			opts.Username = parsed.Username
+			opts.Password = parsed.Password
+			opts.DB = parsed.DB
+			opts.TLSConfig = parsed.TLSConfig
+		}
+	case ModeSentinel:
+		if c.MasterName == "" {
+			return nil, fmt.Errorf("redis.master_name is required in sentinel mode")
+		}
+		if len(c.Addrs) == 0 {
+			return nil, fmt.Errorf("redis.addrs is required in sentinel mode")
+		}
+	case ModeCluster:
+		if len(c.Addrs) == 0 {
+			return nil, fmt.Errorf("redis.addrs is required in cluster mode")
+		}
+	default:
+		return nil, fmt.Errorf("unknown redis.mode %q (expected %s, %s or %s)", c.Mode, ModeStandalone, ModeSentinel, ModeCluster)
+	}
+
+	if len(c.Addrs) > 0 {
+		opts.Addrs = c.Addrs
+	}
+	if c.Username != "" {
+		opts.Username = c.Username
+	}
+	if c.Password != "" {
+		opts.Password = c.Password
+	}
+	if c.DB != 0 {
+		opts.DB = c.DB
+	}
+
+	opts.MasterName = c.MasterName
+	opts.SentinelUsername = c.SentinelUsername
+	opts.SentinelPassword = c.SentinelPassword
+	opts.PoolSize = c.PoolSize
+	opts.MinIdleConns = c.MinIdleConns
+	opts.MaxIdleConns = c.MaxIdleConns
+	opts.ConnMaxIdleTime = c.ConnMaxIdleTime
+	opts.ConnMaxLifetime = c.ConnMaxLifetime
+	opts.PoolTimeout = c.PoolTimeout
+	opts.DialTimeout = c.DialTimeout
+	opts.ReadTimeout = c.ReadTimeout
+	opts.WriteTimeout = c.WriteTimeout
+	opts.MaxRetries = c.MaxRetries
+	opts.ReadOnly = c.ReadOnly
+	opts.RouteByLatency = c.RouteByLatency
+
+	if c.TLS.Enabled {
+		tlsConfig, err := c.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	return opts, nil
+}
+
+// build creates a *tls.Config from the TLS settings.
+func (t TLSConfig) build() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Redis CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in Redis CA file %s", t.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Redis client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,105 @@
+package redis_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/redis"
+)
+
+// TestUniversalOptions verifies that each mode maps configuration onto go-redis options
+func TestUniversalOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupViper func(*viper.Viper)
+		assertOpts func(*testing.T, *redis.Config)
+	}{
+		{
+			name:       "No viper config - standalone from default URL",
+			setupViper: func(v *viper.Viper) {},
+			assertOpts: func(t *testing.T, cfg *redis.Config) {
+				opts, err := cfg.UniversalOptions()
+				require.NoError(t, err)
+				assert.Equal(t, []string{"localhost:6379"}, opts.Addrs)
+				assert.Equal(t, 0, opts.DB)
+				assert.Nil(t, opts.TLSConfig)
+			},
+		},
+		{
+			name: "Explicit fields override URL values",
+			setupViper: func(v *viper.Viper) {
+				v.Set("redis.url", "redis://:secret@cache:6379/1")
+				v.Set("redis.db", 3)
+				v.Set("redis.pool_size", 50)
+				v.Set("redis.read_timeout", "250ms")
+			},
+			assertOpts: func(t *testing.T, cfg *redis.Config) {
+				opts, err := cfg.UniversalOptions()
+				require.NoError(t, err)
+				assert.Equal(t, []string{"cache:6379"}, opts.Addrs)
+				assert.Equal(t, "secret", opts.Password)
+				assert.Equal(t, 3, opts.DB)
+				assert.Equal(t, 50, opts.PoolSize)
+				assert.Equal(t, 250*time.Millisecond, opts.ReadTimeout)
+			},
+		},
+		{
+			name: "Sentinel mode with master name",
+			setupViper: func(v *viper.Viper) {
+				v.Set("redis.mode", "sentinel")
+				v.Set("redis.master_name", "mymaster")
+				v.Set("redis.addrs", []string{"sentinel-1:26379", "sentinel-2:26379"})
+			},
+			assertOpts: func(t *testing.T, cfg *redis.Config) {
+				opts, err := cfg.UniversalOptions()
+				require.NoError(t, err)
+				assert.Equal(t, "mymaster", opts.MasterName)
+				assert.Equal(t, []string{"sentinel-1:26379", "sentinel-2:26379"}, opts.Addrs)
+			},
+		},
+		{
+			name: "Sentinel mode without master name - error",
+			setupViper: func(v *viper.Viper) {
+				v.Set("redis.mode", "sentinel")
+				v.Set("redis.addrs", []string{"sentinel-1:26379"})
+			},
+			assertOpts: func(t *testing.T, cfg *redis.Config) {
+				_, err := cfg.UniversalOptions()
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "Cluster mode without addrs - error",
+			setupViper: func(v *viper.Viper) {
+				v.Set("redis.mode", "cluster")
+			},
+			assertOpts: func(t *testing.T, cfg *redis.Config) {
+				_, err := cfg.UniversalOptions()
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "Unknown mode - error",
+			setupViper: func(v *viper.Viper) {
+				v.Set("redis.mode", "replicated")
+			},
+			assertOpts: func(t *testing.T, cfg *redis.Config) {
+				_, err := cfg.UniversalOptions()
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create fresh viper instance
+			v := viper.New()
+			tt.setupViper(v)
+
+			tt.assertOpts(t, redis.NewConfig(v))
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package redis
+
+// Internals exposed to the external redis_test package.
+var CapTTL = capTTL
+
+// TieredInstance returns the origin c stamps on the invalidations it publishes.
+func TieredInstance(c *TieredCache) string {
+	return c.instance
+}
@@ -1,24 +1,43 @@
 module github.com/things-kit/module/redis
 
-go 1.21
+go 1.23.0
 
 require (
+	github.com/alicebob/miniredis/v2 v2.39.0
 	github.com/redis/go-redis/v9 v9.4.0
 	github.com/spf13/viper v1.18.2
+	github.com/stretchr/testify v1.11.1
 	github.com/things-kit/module/cache v0.0.0
+	github.com/things-kit/module/lock v0.0.0
+	github.com/things-kit/module/log v0.0.0
+	github.com/things-kit/module/memorycache v0.0.0
+	github.com/things-kit/module/metrics v0.0.0
+	github.com/things-kit/module/ratelimit v0.0.0
 	go.uber.org/fx v1.20.1
 )
 
 replace github.com/things-kit/module/cache => ../cache
 
+replace github.com/things-kit/module/lock => ../lock
+
+replace github.com/things-kit/module/log => ../log
+
+replace github.com/things-kit/module/memorycache => ../memorycache
+
+replace github.com/things-kit/module/metrics => ../metrics
+
+replace github.com/things-kit/module/ratelimit => ../ratelimit
+
 require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/magiconair/properties v1.8.7 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
 	github.com/pelletier/go-toml/v2 v2.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/sagikazarmark/locafero v0.4.0 // indirect
 	github.com/sagikazarmark/slog-shim v0.1.0 // indirect
 	github.com/sourcegraph/conc v0.3.0 // indirect
@@ -26,6 +45,7 @@ require (
 	github.com/spf13/cast v1.6.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/subosito/gotenv v1.6.0 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
 	go.uber.org/dig v1.17.1 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	go.uber.org/zap v1.26.0 // indirect
@@ -0,0 +1,294 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/things-kit/module/cache"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/metrics"
+)
+
+// InstrumentedCache implements cache.BatchCache, cache.TaggedCache and
+// cache.PatternCache by recording metrics for every operation before
+// delegating to the wrapped cache. Operations the wrapped cache does not
+// support behave as they do in NamespacedCache.
+//
+// The following instruments are recorded, labelled by operation:
+//
+//	cache_hits_total                 keys found by Get, GetBytes and MGet
+//	cache_misses_total               keys not found by Get, GetBytes and MGet
+//	cache_errors_total               operations that failed (misses excluded)
+//	cache_operation_duration_seconds latency histogram of every operation
+//
+// Operations slower than the configured threshold are logged at warn level.
+type InstrumentedCache struct {
+	next     cache.Cache
+	logger   log.Logger
+	slow     time.Duration
+	hashKeys bool
+
+	hits     metrics.Counter
+	misses   metrics.Counter
+	errors   metrics.Counter
+	duration metrics.Histogram
+}
+
+// NewInstrumentedCache wraps c so that its operations are recorded in m and
+// slow operations are logged to logger.
+func NewInstrumentedCache(c cache.Cache, m metrics.Metrics, logger log.Logger, cfg InstrumentationConfig) *InstrumentedCache {
+	return &InstrumentedCache{
+		next:     c,
+		logger:   logger,
+		slow:     cfg.SlowThreshold,
+		hashKeys: cfg.HashKeys,
+		hits:     m.Counter("cache_hits_total", "Number of cache lookups that found the key.", "operation"),
+		misses:   m.Counter("cache_misses_total", "Number of cache lookups that did not find the key.", "operation"),
+		errors:   m.Counter("cache_errors_total", "Number of cache operations that failed.", "operation"),
+		duration: m.Histogram("cache_operation_duration_seconds", "Latency of cache operations.", nil, "operation"),
+	}
+}
+
+// Get retrieves the value for the given key.
+func (c *InstrumentedCache) Get(ctx context.Context, key string) (string, error) {
+	start := time.Now()
+	value, err := c.next.Get(ctx, key)
+	c.observeLookup(ctx, "get", key, start, err)
+	return value, err
+}
+
+// Set stores a value with the given key and expiration duration.
+func (c *InstrumentedCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	start := time.Now()
+	err := c.next.Set(ctx, key, value, expiration)
+	c.observe(ctx, "set", key, start, err)
+	return err
+}
+
+// Delete removes the key from the cache.
+func (c *InstrumentedCache) Delete(ctx context.Context, key string) error {
+	start := time.Now()
+	err := c.next.Delete(ctx, key)
+	c.observe(ctx, "delete", key, start, err)
+	return err
+}
+
+// Exists checks if a key exists in the cache.
+func (c *InstrumentedCache) Exists(ctx context.Context, key string) (bool, error) {
+	start := time.Now()
+	exists, err := c.next.Exists(ctx, key)
+	c.observe(ctx, "exists", key, start, err)
+	return exists, err
+}
+
+// GetBytes retrieves the raw byte value for the given key.
+func (c *InstrumentedCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	start := time.Now()
+	value, err := c.next.GetBytes(ctx, key)
+	c.observeLookup(ctx, "get_bytes", key, start, err)
+	return value, err
+}
+
+// SetBytes stores a raw byte value with the given key and expiration.
+func (c *InstrumentedCache) SetBytes(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	start := time.Now()
+	err := c.next.SetBytes(ctx, key, value, expiration)
+	c.observe(ctx, "set_bytes", key, start, err)
+	return err
+}
+
+// Expire sets a timeout on a key.
+func (c *InstrumentedCache) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	start := time.Now()
+	ok, err := c.next.Expire(ctx, key, expiration)
+	c.observe(ctx, "expire", key, start, err)
+	return ok, err
+}
+
+// TTL returns the remaining time to live of a key.
+func (c *InstrumentedCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	start := time.Now()
+	ttl, err := c.next.TTL(ctx, key)
+	c.observe(ctx, "ttl", key, start, err)
+	return ttl, err
+}
+
+// Ping tests connectivity to the cache backend.
+func (c *InstrumentedCache) Ping(ctx context.Context) error {
+	return c.next.Ping(ctx)
+}
+
+// Close closes the wrapped cache.
+func (c *InstrumentedCache) Close() error {
+	return c.next.Close()
+}
+
+// MGet retrieves multiple values at once.
+// Each requested key counts as one hit or miss.
+func (c *InstrumentedCache) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	start := time.Now()
+	values, err := c.mget(ctx, keys)
+	c.observe(ctx, "mget", firstKey(keys), start, err)
+	if err == nil {
+		c.hits.Add(float64(len(values)), "mget")
+		c.misses.Add(float64(len(keys)-len(values)), "mget")
+	}
+	return values, err
+}
+
+// MSet sets multiple key-value pairs at once.
+func (c *InstrumentedCache) MSet(ctx context.Context, pairs map[string]string, expiration time.Duration) error {
+	start := time.Now()
+	var err error
+	if batch, ok := c.next.(cache.BatchCache); ok {
+		err = batch.MSet(ctx, pairs, expiration)
+	} else {
+		for key, value := range pairs {
+			if err = c.next.Set(ctx, key, value, expiration); err != nil {
+				break
+			}
+		}
+	}
+
+	var key string
+	for key = range pairs {
+		break
+	}
+	c.observe(ctx, "mset", key, start, err)
+	return err
+}
+
+// MDelete removes multiple keys at once.
+func (c *InstrumentedCache) MDelete(ctx context.Context, keys ...string) error {
+	start := time.Now()
+	var err error
+	if batch, ok := c.next.(cache.BatchCache); ok {
+		err = batch.MDelete(ctx, keys...)
+	} else {
+		for _, key := range keys {
+			if err = c.next.Delete(ctx, key); err != nil {
+				break
+			}
+		}
+	}
+	c.observe(ctx, "mdelete", firstKey(keys), start, err)
+	return err
+}
+
+// SetWithTags stores a value and associates the key with the given tags.
+func (c *InstrumentedCache) SetWithTags(ctx context.Context, key string, value string, expiration time.Duration, tags ...string) error {
+	tagged, ok := c.next.(cache.TaggedCache)
+	if !ok {
+		return cache.ErrNotSupported
+	}
+	start := time.Now()
+	err := tagged.SetWithTags(ctx, key, value, expiration, tags...)
+	c.observe(ctx, "set_with_tags", key, start, err)
+	return err
+}
+
+// SetBytesWithTags stores a raw byte value and associates the key with the given tags.
+func (c *InstrumentedCache) SetBytesWithTags(ctx context.Context, key string, value []byte, expiration time.Duration, tags ...string) error {
+	tagged, ok := c.next.(cache.TaggedCache)
+	if !ok {
+		return cache.ErrNotSupported
+	}
+	start := time.Now()
+	err := tagged.SetBytesWithTags(ctx, key, value, expiration, tags...)
+	c.observe(ctx, "set_bytes_with_tags", key, start, err)
+	return err
+}
+
+// InvalidateTag deletes every key associated with any of the given tags.
+func (c *InstrumentedCache) InvalidateTag(ctx context.Context, tags ...string) error {
+	tagged, ok := c.next.(cache.TaggedCache)
+	if !ok {
+		return cache.ErrNotSupported
+	}
+	start := time.Now()
+	err := tagged.InvalidateTag(ctx, tags...)
+	c.observe(ctx, "invalidate_tag", firstKey(tags), start, err)
+	return err
+}
+
+// DeletePattern deletes all keys matching a glob-style pattern.
+func (c *InstrumentedCache) DeletePattern(ctx context.Context, pattern string) (int64, error) {
+	patterned, ok := c.next.(cache.PatternCache)
+	if !ok {
+		return 0, cache.ErrNotSupported
+	}
+	start := time.Now()
+	deleted, err := patterned.DeletePattern(ctx, pattern)
+	c.observe(ctx, "delete_pattern", pattern, start, err)
+	return deleted, err
+}
+
+// mget delegates to the wrapped cache's MGet, falling back to per-key Gets.
+func (c *InstrumentedCache) mget(ctx context.Context, keys []string) (map[string]string, error) {
+	if batch, ok := c.next.(cache.BatchCache); ok {
+		return batch.MGet(ctx, keys...)
+	}
+
+	result := make(map[string]string, len(keys))
+	for _, key := range keys {
+		value, err := c.next.Get(ctx, key)
+		if errors.Is(err, cache.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// observeLookup records a single-key lookup, counting a miss instead of an error
+// when the key was not found.
+func (c *InstrumentedCache) observeLookup(ctx context.Context, op, key string, start time.Time, err error) {
+	switch {
+	case err == nil:
+		c.hits.Inc(op)
+	case errors.Is(err, cache.ErrNotFound):
+		c.misses.Inc(op)
+		err = nil
+	}
+	c.observe(ctx, op, key, start, err)
+}
+
+// observe records the latency and outcome of an operation and logs it if slow.
+func (c *InstrumentedCache) observe(ctx context.Context, op, key string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	c.duration.Observe(elapsed.Seconds(), op)
+	if err != nil {
+		c.errors.Inc(op)
+	}
+
+	if c.slow > 0 && elapsed >= c.slow {
+		c.logger.WarnC(ctx, "Slow cache operation", err,
+			log.Field{Key: "operation", Value: op},
+			log.Field{Key: "key", Value: c.logKey(key)},
+			log.Field{Key: "duration", Value: elapsed},
+		)
+	}
+}
+
+// logKey returns the key as it should appear in logs.
+func (c *InstrumentedCache) logKey(key string) string {
+	if !c.hashKeys || key == "" {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// firstKey returns the first key of a batch, which identifies it in logs.
+func firstKey(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
@@ -0,0 +1,57 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/memorycache"
+	"github.com/things-kit/module/metrics"
+	"github.com/things-kit/module/redis"
+)
+
+// countingMetrics records counter totals keyed by name and first label value.
+type countingMetrics struct {
+	metrics.Metrics
+	totals map[string]float64
+}
+
+type countingCounter struct {
+	name   string
+	totals map[string]float64
+}
+
+func (m *countingMetrics) Counter(name, _ string, _ ...string) metrics.Counter {
+	return countingCounter{name: name, totals: m.totals}
+}
+
+func (c countingCounter) Inc(labelValues ...string) { c.Add(1, labelValues...) }
+
+func (c countingCounter) Add(delta float64, labelValues ...string) {
+	c.totals[c.name+"/"+labelValues[0]] += delta
+}
+
+// TestInstrumentedCache verifies that hits and misses are counted per operation
+func TestInstrumentedCache(t *testing.T) {
+	ctx := context.Background()
+	m := &countingMetrics{Metrics: metrics.Nop, totals: map[string]float64{}}
+	c := redis.NewInstrumentedCache(memorycache.New(0), m, nil, redis.InstrumentationConfig{})
+
+	require.NoError(t, c.Set(ctx, "a", "1", 0))
+
+	_, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	_, err = c.Get(ctx, "b")
+	require.Error(t, err)
+
+	_, err = c.MGet(ctx, "a", "b", "c")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]float64{
+		"cache_hits_total/get":    1,
+		"cache_misses_total/get":  1,
+		"cache_hits_total/mget":   1,
+		"cache_misses_total/mget": 2,
+	}, m.totals)
+}
@@ -0,0 +1,124 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/things-kit/module/lock"
+)
+
+// lockKeyPrefix namespaces lock keys so they never collide with cache entries.
+// It is appended to redis.key_prefix when one is configured.
+const lockKeyPrefix = "lock:"
+
+var (
+	// releaseScript deletes the lock only if it is still owned by the caller's token.
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+	// extendScript resets the lock's expiration only if it is still owned by the caller's token.
+	extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+)
+
+// RedisLocker implements the lock.Locker interface using Redis.
+// Locks are acquired with SET NX PX and released or extended through Lua
+// scripts that check the owner token, so a process can never free a lock that
+// expired and was taken over by someone else.
+type RedisLocker struct {
+	client        redis.UniversalClient
+	prefix        string
+	retryInterval time.Duration
+}
+
+// NewRedisLocker creates a new Redis-backed locker.
+func NewRedisLocker(client redis.UniversalClient, cfg *Config) *RedisLocker {
+	return &RedisLocker{
+		client:        client,
+		prefix:        cfg.KeyPrefix + lockKeyPrefix,
+		retryInterval: cfg.LockRetryInterval,
+	}
+}
+
+// Acquire attempts to obtain the lock for key once.
+func (l *RedisLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (lock.Lock, error) {
+	if err := lock.CheckTTL(ttl); err != nil {
+		return nil, err
+	}
+
+	token := randomHex(16)
+
+	ok, err := l.client.SetNX(ctx, l.prefix+key, token, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	if !ok {
+		return nil, lock.ErrNotAcquired
+	}
+
+	return &redisLock{client: l.client, key: key, redisKey: l.prefix + key, token: token}, nil
+}
+
+// TryAcquire retries Acquire until the lock is obtained, wait elapses, or ctx is done.
+func (l *RedisLocker) TryAcquire(ctx context.Context, key string, ttl, wait time.Duration) (lock.Lock, error) {
+	return lock.Retry(ctx, wait, l.retryInterval, func(ctx context.Context) (lock.Lock, error) {
+		return l.Acquire(ctx, key, ttl)
+	})
+}
+
+// redisLock is a lock held in Redis.
+type redisLock struct {
+	client   redis.UniversalClient
+	key      string
+	redisKey string
+	token    string
+}
+
+// Key returns the name of the lock.
+func (r *redisLock) Key() string {
+	return r.key
+}
+
+// Token returns the unique owner token of this lock.
+func (r *redisLock) Token() string {
+	return r.token
+}
+
+// Release gives up the lock if it is still owned.
+func (r *redisLock) Release(ctx context.Context) error {
+	n, err := releaseScript.Run(ctx, r.client, []string{r.redisKey}, r.token).Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to release lock %s: %w", r.key, err)
+	}
+	if n == 0 {
+		return lock.ErrNotHeld
+	}
+	return nil
+}
+
+// Extend resets the lock's expiration if it is still owned.
+func (r *redisLock) Extend(ctx context.Context, ttl time.Duration) error {
+	if err := lock.CheckTTL(ttl); err != nil {
+		return err
+	}
+
+	n, err := extendScript.Run(ctx, r.client, []string{r.redisKey}, r.token, ttl.Milliseconds()).Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to extend lock %s: %w", r.key, err)
+	}
+	if n == 0 {
+		return lock.ErrNotHeld
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/lock"
+	"github.com/things-kit/module/redis"
+)
+
+// TestRedisLocker verifies acquisition, contention, owner-checked release and extension after expiry
+func TestRedisLocker(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newMiniredis(t)
+	cfg := redis.NewConfig(nil)
+	cfg.KeyPrefix = "app:"
+	locker := redis.NewRedisLocker(client, cfg)
+
+	t.Run("acquire", func(t *testing.T) {
+		l, err := locker.Acquire(ctx, "acquire", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "acquire", l.Key())
+
+		token, err := mr.Get("app:lock:acquire")
+		require.NoError(t, err)
+		assert.Equal(t, l.Token(), token)
+		assert.Equal(t, time.Minute, mr.TTL("app:lock:acquire"))
+
+		require.NoError(t, l.Release(ctx))
+		assert.False(t, mr.Exists("app:lock:acquire"))
+	})
+
+	t.Run("contention", func(t *testing.T) {
+		held, err := locker.Acquire(ctx, "contention", time.Minute)
+		require.NoError(t, err)
+
+		_, err = locker.Acquire(ctx, "contention", time.Minute)
+		assert.True(t, errors.Is(err, lock.ErrNotAcquired))
+
+		_, err = locker.TryAcquire(ctx, "contention", time.Minute, 20*time.Millisecond)
+		assert.True(t, errors.Is(err, lock.ErrNotAcquired), "should give up after wait")
+
+		require.NoError(t, held.Release(ctx))
+		_, err = locker.Acquire(ctx, "contention", time.Minute)
+		require.NoError(t, err)
+	})
+
+	t.Run("release by non-owner", func(t *testing.T) {
+		stale, err := locker.Acquire(ctx, "takeover", time.Second)
+		require.NoError(t, err)
+		mr.FastForward(2 * time.Second)
+
+		owner, err := locker.Acquire(ctx, "takeover", time.Minute)
+		require.NoError(t, err)
+
+		assert.True(t, errors.Is(stale.Release(ctx), lock.ErrNotHeld))
+		token, err := mr.Get("app:lock:takeover")
+		require.NoError(t, err)
+		assert.Equal(t, owner.Token(), token, "a non-owner must not free the lock")
+	})
+
+	t.Run("extend after expiry", func(t *testing.T) {
+		l, err := locker.Acquire(ctx, "extend", time.Second)
+		require.NoError(t, err)
+
+		require.NoError(t, l.Extend(ctx, time.Minute))
+		assert.Equal(t, time.Minute, mr.TTL("app:lock:extend"))
+
+		mr.FastForward(2 * time.Minute)
+		assert.True(t, errors.Is(l.Extend(ctx, time.Minute), lock.ErrNotHeld))
+		assert.False(t, mr.Exists("app:lock:extend"), "extending an expired lock must not recreate it")
+	})
+
+	t.Run("invalid ttl", func(t *testing.T) {
+		for _, ttl := range []time.Duration{0, time.Microsecond} {
+			_, err := locker.Acquire(ctx, "invalid", ttl)
+			assert.True(t, errors.Is(err, lock.ErrInvalidTTL), "acquire with %s", ttl)
+		}
+		assert.False(t, mr.Exists("app:lock:invalid"), "a lock without expiry must not be created")
+
+		l, err := locker.Acquire(ctx, "invalid", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, errors.Is(l.Extend(ctx, time.Microsecond), lock.ErrInvalidTTL))
+		assert.Equal(t, time.Minute, mr.TTL("app:lock:invalid"), "a rejected extension must not delete the lock")
+	})
+}
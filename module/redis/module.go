@@ -5,65 +5,88 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9"
-	"github.com/spf13/viper"
 	"github.com/things-kit/module/cache"
+	"github.com/things-kit/module/lock"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/metrics"
+	"github.com/things-kit/module/ratelimit"
 	"go.uber.org/fx"
 )
 
 // Module provides the Redis client module to the application.
-// It provides both the cache.Cache interface and the *redis.Client for power users.
+// It provides the cache.Cache, lock.Locker and ratelimit.Limiter interfaces, and the redis.UniversalClient
+// (plus *redis.Client outside cluster mode) for power users.
+// When cache.tiered is enabled, cache.Cache is a TieredCache layered over Redis.
 var Module = fx.Module("redis",
 	fx.Provide(
 		NewConfig,
+		NewTieredConfig,
+		NewScriptRegistry,
 		NewRedisClient,
+		NewClient,
 		NewRedisCache,
-		// Provide as cache.Cache interface
+		NewCache,
+		NewRedisLocker,
+		// Provide as lock.Locker interface
 		fx.Annotate(
-			func(c *RedisCache) cache.Cache { return c },
-			fx.As(new(cache.Cache)),
+			func(l *RedisLocker) lock.Locker { return l },
+			fx.As(new(lock.Locker)),
+		),
+		NewRedisLimiter,
+		// Provide as ratelimit.Limiter interface
+		fx.Annotate(
+			func(l *RedisLimiter) ratelimit.Limiter { return l },
+			fx.As(new(ratelimit.Limiter)),
 		),
 	),
 )
 
-// Config holds the Redis configuration.
-type Config struct {
-	URL string `mapstructure:"url"` // Redis URL (e.g., redis://localhost:6379/0)
-}
-
-// NewConfig creates a new Redis configuration from Viper.
-func NewConfig(v *viper.Viper) *Config {
-	cfg := &Config{
-		URL: "redis://localhost:6379/0", // Default URL
-	}
-
-	// Load configuration from viper
-	if v != nil {
-		_ = v.UnmarshalKey("redis", cfg)
-	}
-
-	return cfg
+// ClientParams contains all dependencies needed to create the Redis client.
+type ClientParams struct {
+	fx.In
+	Lifecycle fx.Lifecycle
+	Logger    log.Logger
+	Config    *Config
+	Scripts   []Script `group:"redis.scripts"`
 }
 
 // NewRedisClient creates a new Redis client with lifecycle management.
-func NewRedisClient(lc fx.Lifecycle, cfg *Config) (*redis.Client, error) {
-	opts, err := redis.ParseURL(cfg.URL)
+// The concrete client depends on redis.mode: *redis.Client for standalone,
+// a failover *redis.Client for sentinel, and *redis.ClusterClient for cluster.
+// Scripts contributed with AsScript are loaded on start and reloaded on new
+// connections to servers that no longer have them.
+func NewRedisClient(p ClientParams) (redis.UniversalClient, error) {
+	cfg := p.Config
+	opts, err := cfg.UniversalOptions()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+		return nil, err
 	}
 
-	client := redis.NewClient(opts)
+	loader := newScriptLoader(p.Logger, p.Scripts)
+	opts.OnConnect = loader.onConnect
+
+	var client redis.UniversalClient
+	switch cfg.Mode {
+	case ModeSentinel:
+		client = redis.NewFailoverClient(opts.Failover())
+	case ModeCluster:
+		client = redis.NewClusterClient(opts.Cluster())
+	default:
+		client = redis.NewClient(opts.Simple())
+	}
 
-	lc.Append(fx.Hook{
+	p.Lifecycle.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			// Test connection on startup
 			if err := client.Ping(ctx).Err(); err != nil {
 				return fmt.Errorf("failed to connect to Redis: %w", err)
 			}
-			return nil
+			return loader.load(ctx, client)
 		},
 		OnStop: func(ctx context.Context) error {
 			return client.Close()
@@ -73,19 +96,77 @@ func NewRedisClient(lc fx.Lifecycle, cfg *Config) (*redis.Client, error) {
 	return client, nil
 }
 
-// RedisCache implements the cache.Cache interface using Redis.
+// NewClient exposes the *redis.Client for code written against the standalone client.
+// It fails in cluster mode, where redis.UniversalClient must be injected instead.
+func NewClient(client redis.UniversalClient) (*redis.Client, error) {
+	c, ok := client.(*redis.Client)
+	if !ok {
+		return nil, fmt.Errorf("*redis.Client is not available with %T; inject redis.UniversalClient instead", client)
+	}
+	return c, nil
+}
+
+// CacheParams contains all dependencies needed to build the provided cache.Cache.
+type CacheParams struct {
+	fx.In
+	Lifecycle fx.Lifecycle
+	Logger    log.Logger
+	Client    redis.UniversalClient
+	Cache     *RedisCache
+	Config    *Config
+	Tiered    *TieredConfig
+	Metrics   metrics.Metrics `optional:"true"`
+}
+
+// NewCache provides the cache.Cache used by the application.
+// It starts from the plain RedisCache and layers the decorators enabled by
+// configuration: the two-tier cache (cache.tiered), key namespacing
+// (redis.key_prefix), then instrumentation (redis.instrumentation.enabled).
+// Metrics are discarded when no metrics.Metrics is provided.
+func NewCache(p CacheParams) cache.Cache {
+	var c cache.Cache = p.Cache
+	if p.Tiered.Enabled {
+		c = NewTieredCache(p.Lifecycle, p.Tiered, p.Client, p.Cache, p.Logger)
+	}
+	if p.Config.KeyPrefix != "" {
+		c = NewNamespacedCache(c, p.Config.KeyPrefix)
+	}
+	if p.Config.Instrumentation.Enabled {
+		m := p.Metrics
+		if m == nil {
+			m = metrics.Nop
+		}
+		c = NewInstrumentedCache(c, m, p.Logger, p.Config.Instrumentation)
+	}
+	return c
+}
+
+// errNotFound is returned on a cache miss. It matches both cache.ErrNotFound
+// and redis.Nil so existing errors.Is(err, redis.Nil) checks keep working.
+var errNotFound = fmt.Errorf("%w: %w", cache.ErrNotFound, redis.Nil)
+
+// translateError maps go-redis errors onto the cache package's sentinel errors.
+func translateError(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return errNotFound
+	}
+	return err
+}
+
+// RedisCache implements the cache.BatchCache interface using Redis.
 type RedisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
 // NewRedisCache creates a new Redis cache implementation.
-func NewRedisCache(client *redis.Client) *RedisCache {
+func NewRedisCache(client redis.UniversalClient) *RedisCache {
 	return &RedisCache{client: client}
 }
 
 // Get retrieves the value for the given key.
 func (c *RedisCache) Get(ctx context.Context, key string) (string, error) {
-	return c.client.Get(ctx, key).Result()
+	value, err := c.client.Get(ctx, key).Result()
+	return value, translateError(err)
 }
 
 // Set stores a value with the given key and expiration duration.
@@ -109,7 +190,8 @@ func (c *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
 
 // GetBytes retrieves the raw byte value for the given key.
 func (c *RedisCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
-	return c.client.Get(ctx, key).Bytes()
+	value, err := c.client.Get(ctx, key).Bytes()
+	return value, translateError(err)
 }
 
 // SetBytes stores a raw byte value with the given key and expiration.
@@ -136,3 +218,56 @@ func (c *RedisCache) Ping(ctx context.Context) error {
 func (c *RedisCache) Close() error {
 	return c.client.Close()
 }
+
+// MGet retrieves multiple values at once.
+// Keys are fetched in a pipeline so that they may live in different cluster slots.
+func (c *RedisCache) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(keys))
+	for i, cmd := range cmds {
+		value, err := cmd.Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[keys[i]] = value
+	}
+	return result, nil
+}
+
+// MSet sets multiple key-value pairs at once.
+func (c *RedisCache) MSet(ctx context.Context, pairs map[string]string, expiration time.Duration) error {
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range pairs {
+			pipe.Set(ctx, key, value, expiration)
+		}
+		return nil
+	})
+	return err
+}
+
+// MDelete removes multiple keys at once.
+func (c *RedisCache) MDelete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,342 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/things-kit/module/cache"
+	"go.uber.org/fx"
+)
+
+// ErrNoTenant is returned by a tenant-aware cache when the context carries no tenant.
+// Requests without a tenant are rejected rather than sharing a global namespace.
+var ErrNoTenant = errors.New("cache: no tenant in context")
+
+// tenantKey is the context key under which the current tenant is stored.
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx carrying the given tenant identifier.
+// Transport middleware typically calls this after authenticating a request.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant identifier stored by WithTenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// PrefixFunc derives the key prefix for an operation from its context.
+type PrefixFunc func(ctx context.Context) (string, error)
+
+// NamespacedCache implements cache.BatchCache by prefixing every key before
+// delegating to the wrapped cache. Batch operations use the wrapped cache's
+// batch methods when it implements cache.BatchCache, and fall back to
+// per-key calls otherwise.
+//
+// It also implements cache.TaggedCache and cache.PatternCache, namespacing tags
+// and patterns the same way; these return cache.ErrNotSupported when the
+// wrapped cache does not implement the corresponding interface. Over this
+// package's Redis caches, tag sets are stored under the prefix as well.
+type NamespacedCache struct {
+	next   cache.Cache
+	prefix PrefixFunc
+}
+
+// NewNamespacedCache wraps c so that every key is stored under prefix.
+//
+// Example:
+//
+//	orders := redis.NewNamespacedCache(c, "orders:")
+//	orders.Set(ctx, "42", data, time.Hour) // Stored as "orders:42"
+func NewNamespacedCache(c cache.Cache, prefix string) *NamespacedCache {
+	return NewPrefixFuncCache(c, func(context.Context) (string, error) {
+		return prefix, nil
+	})
+}
+
+// NewTenantCache wraps c so that every key is stored under prefix followed by
+// the tenant from the operation's context and a colon, e.g. "app:acme:user:42".
+// Operations whose context carries no tenant fail with ErrNoTenant.
+func NewTenantCache(c cache.Cache, prefix string) *NamespacedCache {
+	return NewPrefixFuncCache(c, func(ctx context.Context) (string, error) {
+		tenant, ok := TenantFromContext(ctx)
+		if !ok {
+			return "", ErrNoTenant
+		}
+		return prefix + tenant + ":", nil
+	})
+}
+
+// NewPrefixFuncCache wraps c so that every key is prefixed by the result of fn.
+// Use it to derive namespaces from custom context values.
+func NewPrefixFuncCache(c cache.Cache, fn PrefixFunc) *NamespacedCache {
+	return &NamespacedCache{next: c, prefix: fn}
+}
+
+// Get retrieves the value for the given key.
+func (c *NamespacedCache) Get(ctx context.Context, key string) (string, error) {
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return "", err
+	}
+	return c.next.Get(ctx, prefix+key)
+}
+
+// Set stores a value with the given key and expiration duration.
+func (c *NamespacedCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return err
+	}
+	return c.next.Set(ctx, prefix+key, value, expiration)
+}
+
+// Delete removes the key from the cache.
+func (c *NamespacedCache) Delete(ctx context.Context, key string) error {
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return err
+	}
+	return c.next.Delete(ctx, prefix+key)
+}
+
+// Exists checks if a key exists in the cache.
+func (c *NamespacedCache) Exists(ctx context.Context, key string) (bool, error) {
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return false, err
+	}
+	return c.next.Exists(ctx, prefix+key)
+}
+
+// GetBytes retrieves the raw byte value for the given key.
+func (c *NamespacedCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.next.GetBytes(ctx, prefix+key)
+}
+
+// SetBytes stores a raw byte value with the given key and expiration.
+func (c *NamespacedCache) SetBytes(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return err
+	}
+	return c.next.SetBytes(ctx, prefix+key, value, expiration)
+}
+
+// Expire sets a timeout on a key.
+func (c *NamespacedCache) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return false, err
+	}
+	return c.next.Expire(ctx, prefix+key, expiration)
+}
+
+// TTL returns the remaining time to live of a key.
+func (c *NamespacedCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return c.next.TTL(ctx, prefix+key)
+}
+
+// Ping tests connectivity to the cache backend.
+func (c *NamespacedCache) Ping(ctx context.Context) error {
+	return c.next.Ping(ctx)
+}
+
+// Close closes the wrapped cache.
+func (c *NamespacedCache) Close() error {
+	return c.next.Close()
+}
+
+// MGet retrieves multiple values at once.
+// The returned map is keyed by the unprefixed keys.
+func (c *NamespacedCache) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(keys))
+	if batch, ok := c.next.(cache.BatchCache); ok {
+		values, err := batch.MGet(ctx, prefixKeys(prefix, keys)...)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if value, ok := values[prefix+key]; ok {
+				result[key] = value
+			}
+		}
+		return result, nil
+	}
+
+	for _, key := range keys {
+		value, err := c.next.Get(ctx, prefix+key)
+		if errors.Is(err, cache.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// MSet sets multiple key-value pairs at once.
+func (c *NamespacedCache) MSet(ctx context.Context, pairs map[string]string, expiration time.Duration) error {
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return err
+	}
+
+	if batch, ok := c.next.(cache.BatchCache); ok {
+		prefixed := make(map[string]string, len(pairs))
+		for key, value := range pairs {
+			prefixed[prefix+key] = value
+		}
+		return batch.MSet(ctx, prefixed, expiration)
+	}
+
+	for key, value := range pairs {
+		if err := c.next.Set(ctx, prefix+key, value, expiration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MDelete removes multiple keys at once.
+func (c *NamespacedCache) MDelete(ctx context.Context, keys ...string) error {
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return err
+	}
+
+	if batch, ok := c.next.(cache.BatchCache); ok {
+		return batch.MDelete(ctx, prefixKeys(prefix, keys)...)
+	}
+
+	for _, key := range keys {
+		if err := c.next.Delete(ctx, prefix+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetWithTags stores a value and associates the key with the given tags.
+func (c *NamespacedCache) SetWithTags(ctx context.Context, key string, value string, expiration time.Duration, tags ...string) error {
+	tagged, ok := c.next.(cache.TaggedCache)
+	if !ok {
+		return cache.ErrNotSupported
+	}
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return err
+	}
+	if sets, ok := c.next.(tagSetCache); ok {
+		return sets.setWithTagKeys(ctx, prefix+key, value, expiration, prefixKeys(prefix, tagKeys(tags)))
+	}
+	return tagged.SetWithTags(ctx, prefix+key, value, expiration, prefixKeys(prefix, tags)...)
+}
+
+// SetBytesWithTags stores a raw byte value and associates the key with the given tags.
+func (c *NamespacedCache) SetBytesWithTags(ctx context.Context, key string, value []byte, expiration time.Duration, tags ...string) error {
+	tagged, ok := c.next.(cache.TaggedCache)
+	if !ok {
+		return cache.ErrNotSupported
+	}
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return err
+	}
+	if sets, ok := c.next.(tagSetCache); ok {
+		return sets.setWithTagKeys(ctx, prefix+key, value, expiration, prefixKeys(prefix, tagKeys(tags)))
+	}
+	return tagged.SetBytesWithTags(ctx, prefix+key, value, expiration, prefixKeys(prefix, tags)...)
+}
+
+// InvalidateTag deletes every key associated with any of the given tags.
+func (c *NamespacedCache) InvalidateTag(ctx context.Context, tags ...string) error {
+	tagged, ok := c.next.(cache.TaggedCache)
+	if !ok {
+		return cache.ErrNotSupported
+	}
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return err
+	}
+	if sets, ok := c.next.(tagSetCache); ok {
+		_, err := sets.invalidateTagKeys(ctx, prefixKeys(prefix, tagKeys(tags)))
+		return err
+	}
+	return tagged.InvalidateTag(ctx, prefixKeys(prefix, tags)...)
+}
+
+// DeletePattern deletes all keys in the namespace matching pattern.
+// Glob characters in the prefix are escaped so they match literally.
+func (c *NamespacedCache) DeletePattern(ctx context.Context, pattern string) (int64, error) {
+	patterned, ok := c.next.(cache.PatternCache)
+	if !ok {
+		return 0, cache.ErrNotSupported
+	}
+	prefix, err := c.prefix(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return patterned.DeletePattern(ctx, escapeGlob(prefix)+pattern)
+}
+
+// escapeGlob escapes the characters that are special in Redis glob patterns.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// prefixKeys returns a copy of keys with prefix prepended to each.
+func prefixKeys(prefix string, keys []string) []string {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = prefix + key
+	}
+	return prefixed
+}
+
+// AsNamespacedCache provides a named cache.Cache that wraps the application's
+// cache.Cache with the given key prefix. Inject it with the matching name tag.
+//
+// Example:
+//
+//	redis.AsNamespacedCache("orders", "orders:")
+//
+//	type OrderRepoParams struct {
+//	    fx.In
+//	    Cache cache.Cache `name:"orders"`
+//	}
+func AsNamespacedCache(name, prefix string) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			func(c cache.Cache) cache.Cache { return NewNamespacedCache(c, prefix) },
+			fx.ResultTags(`name:"`+name+`"`),
+		),
+	)
+}
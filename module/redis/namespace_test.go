@@ -0,0 +1,81 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/memorycache"
+	"github.com/things-kit/module/redis"
+)
+
+// TestNamespacedCache verifies that keys are prefixed on write and unprefixed in batch results
+func TestNamespacedCache(t *testing.T) {
+	ctx := context.Background()
+	backend := memorycache.New(0)
+	orders := redis.NewNamespacedCache(backend, "orders:")
+
+	require.NoError(t, orders.Set(ctx, "42", "pending", time.Minute))
+
+	raw, err := backend.Get(ctx, "orders:42")
+	require.NoError(t, err)
+	assert.Equal(t, "pending", raw)
+
+	require.NoError(t, orders.MSet(ctx, map[string]string{"43": "paid"}, time.Minute))
+	values, err := orders.MGet(ctx, "42", "43", "44")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"42": "pending", "43": "paid"}, values)
+
+	require.NoError(t, orders.MDelete(ctx, "42", "43"))
+	assert.Equal(t, 0, backend.Len())
+}
+
+// TestTenantCache verifies that tenants are isolated and a missing tenant is rejected
+func TestTenantCache(t *testing.T) {
+	backend := memorycache.New(0)
+	c := redis.NewTenantCache(backend, "app:")
+
+	acme := redis.WithTenant(context.Background(), "acme")
+	globex := redis.WithTenant(context.Background(), "globex")
+
+	require.NoError(t, c.Set(acme, "user:1", "alice", 0))
+
+	value, err := c.Get(acme, "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", value)
+
+	exists, err := c.Exists(globex, "user:1")
+	require.NoError(t, err)
+	assert.False(t, exists, "tenants must not see each other's keys")
+
+	exists, err = backend.Exists(context.Background(), "app:acme:user:1")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	err = c.Set(context.Background(), "user:1", "mallory", 0)
+	assert.True(t, errors.Is(err, redis.ErrNoTenant))
+}
+
+// TestNamespacedTagsAndPatterns verifies that tags and patterns stay within the namespace
+func TestNamespacedTagsAndPatterns(t *testing.T) {
+	ctx := context.Background()
+	backend := memorycache.New(0)
+	a := redis.NewNamespacedCache(backend, "svc-a:")
+	b := redis.NewNamespacedCache(backend, "svc-b:")
+
+	require.NoError(t, a.SetWithTags(ctx, "user:42", "a", 0, "user:42"))
+	require.NoError(t, b.SetWithTags(ctx, "user:42", "b", 0, "user:42"))
+
+	require.NoError(t, a.InvalidateTag(ctx, "user:42"))
+	exists, err := b.Exists(ctx, "user:42")
+	require.NoError(t, err)
+	assert.True(t, exists, "invalidating a tag must not cross namespaces")
+
+	deleted, err := b.DeletePattern(ctx, "user:*")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Equal(t, 0, backend.Len())
+}
@@ -0,0 +1,142 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/things-kit/module/ratelimit"
+)
+
+// rateLimitKeyPrefix namespaces rate limit keys so they never collide with cache entries.
+// It is appended to redis.key_prefix when one is configured.
+const rateLimitKeyPrefix = "ratelimit:"
+
+// Both scripts read the clock with TIME so that every replica sees the same time,
+// work in microseconds, and return {allowed, remaining, retry_after_us, reset_after_us}.
+var (
+	// tokenBucketScript refills the bucket stored in a hash and takes one token.
+	tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(state[1])
+local last = tonumber(state[2])
+if tokens == nil or last == nil then
+	tokens = burst
+	last = now
+end
+
+local per_us = rate / period
+tokens = math.min(burst, tokens + math.max(0, now - last) * per_us)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / per_us)
+end
+
+local reset = math.ceil((burst - tokens) / per_us)
+redis.call("HSET", KEYS[1], "tokens", string.format("%.17g", tokens), "last", string.format("%d", now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(reset / 1000) + 1)
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+	// slidingWindowScript counts requests in fixed windows and weights the
+	// previous window by its overlap with the sliding period.
+	slidingWindowScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local index = math.floor(now / period)
+
+local state = redis.call("HMGET", KEYS[1], "window", "cur", "prev")
+local window = tonumber(state[1])
+local cur = tonumber(state[2]) or 0
+local prev = tonumber(state[3]) or 0
+if window == index - 1 then
+	prev = cur
+	cur = 0
+elseif window ~= index then
+	prev = 0
+	cur = 0
+end
+
+local remaining_in_window = period - (now - index * period)
+local count = prev * remaining_in_window / period + cur
+
+local allowed = 0
+local retry = 0
+if count + 1 <= rate then
+	cur = cur + 1
+	count = count + 1
+	allowed = 1
+elseif cur + 1 > rate then
+	retry = remaining_in_window + period * (1 - (rate - 1) / cur)
+else
+	retry = remaining_in_window - (rate - cur - 1) * period / prev
+end
+
+local reset = remaining_in_window
+if cur > 0 then
+	reset = reset + period
+end
+redis.call("HSET", KEYS[1], "window", string.format("%d", index), "cur", cur, "prev", prev)
+redis.call("PEXPIRE", KEYS[1], math.ceil(reset / 1000) + 1)
+return {allowed, math.max(0, math.floor(rate - count)), math.ceil(retry), reset}
+`)
+)
+
+// RedisLimiter implements the ratelimit.Limiter interface using Redis.
+// Each check is a single Lua script, so limits are enforced atomically across
+// all replicas sharing the Redis deployment.
+type RedisLimiter struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisLimiter creates a new Redis-backed rate limiter.
+func NewRedisLimiter(client redis.UniversalClient, cfg *Config) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+		prefix: cfg.KeyPrefix + rateLimitKeyPrefix,
+	}
+}
+
+// Allow records one request for key and reports whether it is within limit.
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	limit, err := limit.Normalize()
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	script, args, capacity := tokenBucketScript, []any{limit.Rate, limit.Period.Microseconds(), limit.Burst}, limit.Burst
+	if limit.Algorithm == ratelimit.SlidingWindow {
+		script, args, capacity = slidingWindowScript, []any{limit.Rate, limit.Period.Microseconds()}, limit.Rate
+	}
+
+	values, err := script.Run(ctx, l.client, []string{l.prefix + key}, args...).Int64Slice()
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("failed to check rate limit %s: %w", key, err)
+	}
+	if len(values) != 4 {
+		return ratelimit.Result{}, fmt.Errorf("failed to check rate limit %s: unexpected script result %v", key, values)
+	}
+
+	return ratelimit.Result{
+		Allowed:    values[0] == 1,
+		Limit:      capacity,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+		ResetAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
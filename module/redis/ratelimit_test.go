@@ -0,0 +1,107 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/ratelimit"
+	"github.com/things-kit/module/redis"
+)
+
+// windowStart is aligned to a minute so that sliding windows start at known times
+var windowStart = time.Unix(1_700_000_040, 0)
+
+// step is one rate limit check at an offset from windowStart
+type step struct {
+	at         time.Duration
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+	resetAfter time.Duration
+}
+
+// TestRedisLimiter verifies burst, refill and Retry-After values of both scripts
+func TestRedisLimiter(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit ratelimit.Limit
+		steps []step
+	}{
+		{
+			name:  "token bucket",
+			limit: ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Rate: 2, Period: time.Second, Burst: 4},
+			steps: []step{
+				// The full burst is available at once.
+				{at: 0, allowed: true, remaining: 3, resetAfter: 500 * time.Millisecond},
+				{at: 0, allowed: true, remaining: 2, resetAfter: time.Second},
+				{at: 0, allowed: true, remaining: 1, resetAfter: 1500 * time.Millisecond},
+				{at: 0, allowed: true, remaining: 0, resetAfter: 2 * time.Second},
+				{at: 0, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond, resetAfter: 2 * time.Second},
+				// One token is refilled every 500ms.
+				{at: 250 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 250 * time.Millisecond, resetAfter: 1750 * time.Millisecond},
+				{at: 500 * time.Millisecond, allowed: true, remaining: 0, resetAfter: 2 * time.Second},
+				// Refill stops at the burst.
+				{at: time.Minute, allowed: true, remaining: 3, resetAfter: 500 * time.Millisecond},
+			},
+		},
+		{
+			name:  "sliding window",
+			limit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Rate: 3, Period: time.Minute},
+			steps: []step{
+				{at: 30 * time.Second, allowed: true, remaining: 2, resetAfter: 90 * time.Second},
+				{at: 30 * time.Second, allowed: true, remaining: 1, resetAfter: 90 * time.Second},
+				{at: 30 * time.Second, allowed: true, remaining: 0, resetAfter: 90 * time.Second},
+				// The previous window weighs 2/3 at 20s into the next one.
+				{at: 30 * time.Second, allowed: false, remaining: 0, retryAfter: 50 * time.Second, resetAfter: 90 * time.Second},
+				{at: 70 * time.Second, allowed: false, remaining: 0, retryAfter: 10 * time.Second, resetAfter: 50 * time.Second},
+				{at: 80 * time.Second, allowed: true, remaining: 0, resetAfter: 100 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mr, client := newMiniredis(t)
+			limiter := redis.NewRedisLimiter(client, redis.NewConfig(nil))
+
+			for i, s := range tt.steps {
+				mr.SetTime(windowStart.Add(s.at))
+				result, err := limiter.Allow(ctx, "client", tt.limit)
+				require.NoError(t, err)
+				assert.Equal(t, s.allowed, result.Allowed, "step %d allowed", i)
+				assert.Equal(t, s.remaining, result.Remaining, "step %d remaining", i)
+				assert.Equal(t, s.retryAfter, result.RetryAfter, "step %d retry after", i)
+				assert.Equal(t, s.resetAfter, result.ResetAfter, "step %d reset after", i)
+			}
+		})
+	}
+}
+
+// TestRedisLimiterKeys verifies that keys are limited independently under the configured prefix
+func TestRedisLimiterKeys(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newMiniredis(t)
+	cfg := redis.NewConfig(nil)
+	cfg.KeyPrefix = "app:"
+	limiter := redis.NewRedisLimiter(client, cfg)
+	limit := ratelimit.Limit{Rate: 1, Period: time.Minute}
+
+	result, err := limiter.Allow(ctx, "a", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Limit)
+
+	result, err = limiter.Allow(ctx, "b", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed, "keys must be limited independently")
+
+	assert.True(t, mr.Exists("app:ratelimit:a"))
+	assert.Greater(t, mr.TTL("app:ratelimit:a"), time.Duration(0), "state must expire once the allowance is back")
+
+	_, err = limiter.Allow(ctx, "a", ratelimit.Limit{})
+	assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+}
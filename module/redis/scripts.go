@@ -0,0 +1,168 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+)
+
+// ErrScriptNotFound is returned when running a script name that was never registered.
+var ErrScriptNotFound = errors.New("redis: script not registered")
+
+// Script is a named Lua script contributed to the registry with AsScript.
+type Script struct {
+	Name   string
+	Source string
+}
+
+// ScriptRegistry runs Lua scripts by name.
+// Scripts are loaded with SCRIPT LOAD when the client starts, and again on every
+// new connection to a server that no longer has them (e.g. after a failover or
+// restart). They are invoked with EVALSHA, falling back to EVAL on NOSCRIPT, so
+// a missing script never fails a call.
+type ScriptRegistry struct {
+	client  redis.UniversalClient
+	scripts map[string]*redis.Script
+}
+
+// ScriptRegistryParams contains all dependencies needed to build the script registry.
+type ScriptRegistryParams struct {
+	fx.In
+	Client  redis.UniversalClient
+	Scripts []Script `group:"redis.scripts"`
+}
+
+// NewScriptRegistry creates a registry of the scripts contributed with AsScript.
+// It fails if a script has no name or two scripts share a name.
+func NewScriptRegistry(p ScriptRegistryParams) (*ScriptRegistry, error) {
+	r := &ScriptRegistry{
+		client:  p.Client,
+		scripts: make(map[string]*redis.Script, len(p.Scripts)),
+	}
+
+	for _, s := range p.Scripts {
+		if s.Name == "" {
+			return nil, fmt.Errorf("redis script must have a name")
+		}
+		if _, ok := r.scripts[s.Name]; ok {
+			return nil, fmt.Errorf("redis script %s registered more than once", s.Name)
+		}
+		r.scripts[s.Name] = redis.NewScript(s.Source)
+	}
+
+	return r, nil
+}
+
+// Run executes the named script with EVALSHA, falling back to EVAL if the
+// server does not have it cached.
+func (r *ScriptRegistry) Run(ctx context.Context, name string, keys []string, args ...any) *redis.Cmd {
+	script, ok := r.scripts[name]
+	if !ok {
+		return scriptNotFound(ctx, name)
+	}
+	return script.Run(ctx, r.client, keys, args...)
+}
+
+// RunRO executes the named read-only script with EVALSHA_RO, falling back to
+// EVAL_RO. Read-only scripts may be routed to replicas.
+func (r *ScriptRegistry) RunRO(ctx context.Context, name string, keys []string, args ...any) *redis.Cmd {
+	script, ok := r.scripts[name]
+	if !ok {
+		return scriptNotFound(ctx, name)
+	}
+	return script.RunRO(ctx, r.client, keys, args...)
+}
+
+// Hash returns the SHA1 of the named script, for callers using EvalSha directly.
+func (r *ScriptRegistry) Hash(name string) (string, bool) {
+	script, ok := r.scripts[name]
+	if !ok {
+		return "", false
+	}
+	return script.Hash(), true
+}
+
+// scriptNotFound returns a command failed with ErrScriptNotFound.
+func scriptNotFound(ctx context.Context, name string) *redis.Cmd {
+	cmd := redis.NewCmd(ctx)
+	cmd.SetErr(fmt.Errorf("%w: %s", ErrScriptNotFound, name))
+	return cmd
+}
+
+// scriptLoader loads the registered scripts into the servers the client connects to.
+type scriptLoader struct {
+	logger  log.Logger
+	scripts []Script
+	hashes  []string // SHA1 of each entry in scripts
+}
+
+// newScriptLoader creates a loader for the given scripts.
+func newScriptLoader(logger log.Logger, scripts []Script) *scriptLoader {
+	l := &scriptLoader{logger: logger, scripts: scripts}
+	for _, s := range scripts {
+		l.hashes = append(l.hashes, redis.NewScript(s.Source).Hash())
+	}
+	return l
+}
+
+// load loads every script with SCRIPT LOAD, on every shard in cluster mode.
+// Scripts that fail to compile are reported here, at startup.
+func (l *scriptLoader) load(ctx context.Context, client redis.UniversalClient) error {
+	for _, s := range l.scripts {
+		if err := client.ScriptLoad(ctx, s.Source).Err(); err != nil {
+			return fmt.Errorf("failed to load redis script %s: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+// onConnect reloads the scripts missing from the server a new connection points at.
+// Failures are logged rather than returned so that they never prevent connecting;
+// ScriptRegistry.Run falls back to EVAL for scripts that could not be loaded.
+func (l *scriptLoader) onConnect(ctx context.Context, cn *redis.Conn) error {
+	if len(l.hashes) == 0 {
+		return nil
+	}
+
+	exists, err := cn.ScriptExists(ctx, l.hashes...).Result()
+	if err != nil {
+		l.logger.WarnC(ctx, "Failed to check Redis scripts on new connection", err)
+		return nil
+	}
+
+	for i, s := range l.scripts {
+		if i < len(exists) && exists[i] {
+			continue
+		}
+		if err := cn.ScriptLoad(ctx, s.Source).Err(); err != nil {
+			l.logger.WarnC(ctx, "Failed to reload Redis script", err, log.Field{Key: "script", Value: s.Name})
+		}
+	}
+	return nil
+}
+
+// AsScript contributes a named Lua script to the registry.
+// Scripts are loaded when the Redis client starts and run with ScriptRegistry.Run.
+//
+// Example:
+//
+//	redis.AsScript("incr_capped", `
+//	local v = redis.call("INCR", KEYS[1])
+//	if v > tonumber(ARGV[1]) then redis.call("SET", KEYS[1], ARGV[1]) return tonumber(ARGV[1]) end
+//	return v
+//	`)
+//
+//	// Later, in a service that injects *redis.ScriptRegistry:
+//	n, err := scripts.Run(ctx, "incr_capped", []string{"counter"}, 100).Int64()
+func AsScript(name, source string) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			func() Script { return Script{Name: name, Source: source} },
+			fx.ResultTags(`group:"redis.scripts"`),
+		),
+	)
+}
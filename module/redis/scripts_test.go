@@ -0,0 +1,111 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/redis"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+// incrCapped is the script contributed in the registry tests
+const incrCapped = `
+local v = redis.call("INCR", KEYS[1])
+if v > tonumber(ARGV[1]) then redis.call("SET", KEYS[1], ARGV[1]) return tonumber(ARGV[1]) end
+return v
+`
+
+// startScripts starts a Redis client and script registry against mr with the given scripts
+func startScripts(t *testing.T, mr *miniredis.Miniredis, scripts ...fx.Option) (*redis.ScriptRegistry, goredis.UniversalClient) {
+	t.Helper()
+	cfg := redis.NewConfig(nil)
+	cfg.URL = "redis://" + mr.Addr()
+
+	var (
+		registry *redis.ScriptRegistry
+		client   goredis.UniversalClient
+	)
+	app := fxtest.New(t,
+		fx.Supply(cfg),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		fx.Provide(redis.NewRedisClient, redis.NewScriptRegistry),
+		fx.Options(scripts...),
+		fx.Populate(&registry, &client),
+	)
+	app.RequireStart()
+	t.Cleanup(app.RequireStop)
+	return registry, client
+}
+
+// scriptLoaded reports whether the server has the named script cached
+func scriptLoaded(t *testing.T, registry *redis.ScriptRegistry, client goredis.UniversalClient, name string) bool {
+	t.Helper()
+	hash, ok := registry.Hash(name)
+	require.True(t, ok)
+	exists, err := client.ScriptExists(context.Background(), hash).Result()
+	require.NoError(t, err)
+	return exists[0]
+}
+
+// TestScriptRegistry verifies preloading, the EVAL fallback, reloading on reconnect and unknown names
+func TestScriptRegistry(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	registry, client := startScripts(t, mr, redis.AsScript("incr_capped", incrCapped))
+
+	assert.True(t, scriptLoaded(t, registry, client, "incr_capped"), "scripts must be loaded on start")
+
+	n, err := registry.Run(ctx, "incr_capped", []string{"counter"}, 2).Int64()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	require.NoError(t, client.ScriptFlush(ctx).Err())
+	n, err = registry.Run(ctx, "incr_capped", []string{"counter"}, 2).Int64()
+	require.NoError(t, err, "a flushed script must fall back to EVAL")
+	assert.Equal(t, int64(2), n)
+
+	require.NoError(t, client.ScriptFlush(ctx).Err())
+	mr.Close() // Drops every connection; the next command reconnects
+	require.NoError(t, mr.Restart())
+	assert.True(t, scriptLoaded(t, registry, client, "incr_capped"), "scripts must be reloaded on a new connection")
+
+	err = registry.Run(ctx, "missing", nil).Err()
+	assert.ErrorIs(t, err, redis.ErrScriptNotFound)
+	err = registry.RunRO(ctx, "missing", nil).Err()
+	assert.ErrorIs(t, err, redis.ErrScriptNotFound)
+	_, ok := registry.Hash("missing")
+	assert.False(t, ok)
+}
+
+// TestScriptRegistryNames verifies that scripts must have unique, non-empty names
+func TestScriptRegistryNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		scripts []redis.Script
+		wantErr string
+	}{
+		{
+			name:    "duplicate name",
+			scripts: []redis.Script{{Name: "a", Source: "return 1"}, {Name: "a", Source: "return 2"}},
+			wantErr: "redis script a registered more than once",
+		},
+		{
+			name:    "empty name",
+			scripts: []redis.Script{{Source: "return 1"}},
+			wantErr: "redis script must have a name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := redis.NewScriptRegistry(redis.ScriptRegistryParams{Scripts: tt.scripts})
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
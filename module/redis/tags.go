@@ -0,0 +1,189 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// tagKeyPrefix names the Redis set that holds the keys carrying a tag.
+	tagKeyPrefix = "_tag:"
+
+	// scanCount is the SCAN COUNT hint used by DeletePattern.
+	scanCount = 500
+)
+
+// popTagScript atomically reads and deletes a tag set, so keys tagged while an
+// invalidation is in progress are never dropped from the set unseen.
+var popTagScript = redis.NewScript(`
+local keys = redis.call("SMEMBERS", KEYS[1])
+redis.call("DEL", KEYS[1])
+return keys
+`)
+
+// addTagScript adds a key to a tag set and extends the set's expiration so that
+// it lives at least as long as the key (ARGV[2] milliseconds, 0 = no expiration).
+// A set that already outlives the key, or never expires, is left as it is.
+var addTagScript = redis.NewScript(`
+local current = redis.call("PTTL", KEYS[1])
+redis.call("SADD", KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	redis.call("PERSIST", KEYS[1])
+elseif current == -2 or (current >= 0 and current < ttl) then
+	redis.call("PEXPIRE", KEYS[1], ttl)
+end
+return 1
+`)
+
+// tagSetCache is implemented by the caches in this package that keep tag sets
+// in Redis. NamespacedCache uses it to store a tag's set under its own prefix
+// ("<prefix>_tag:<tag>") rather than under a prefixed tag name.
+type tagSetCache interface {
+	setWithTagKeys(ctx context.Context, key string, value any, expiration time.Duration, tagKeys []string) error
+	invalidateTagKeys(ctx context.Context, tagKeys []string) ([]string, error)
+}
+
+// SetWithTags stores a value and associates the key with the given tags.
+// Each tag is a Redis set of keys that expires no earlier than the keys added
+// to it, and is removed by InvalidateTag. Members whose keys have already
+// expired are harmless.
+func (c *RedisCache) SetWithTags(ctx context.Context, key string, value string, expiration time.Duration, tags ...string) error {
+	return c.setWithTagKeys(ctx, key, value, expiration, tagKeys(tags))
+}
+
+// SetBytesWithTags stores a raw byte value and associates the key with the given tags.
+func (c *RedisCache) SetBytesWithTags(ctx context.Context, key string, value []byte, expiration time.Duration, tags ...string) error {
+	return c.setWithTagKeys(ctx, key, value, expiration, tagKeys(tags))
+}
+
+// InvalidateTag deletes every key associated with any of the given tags.
+func (c *RedisCache) InvalidateTag(ctx context.Context, tags ...string) error {
+	_, err := c.invalidateTagKeys(ctx, tagKeys(tags))
+	return err
+}
+
+// DeletePattern deletes all keys matching a glob-style pattern and returns the
+// number of keys deleted. Keys are found with SCAN, never KEYS, so Redis is not
+// blocked; in cluster mode every master is scanned. Keys written while the scan
+// runs may or may not be deleted.
+func (c *RedisCache) DeletePattern(ctx context.Context, pattern string) (int64, error) {
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		var deleted atomic.Int64
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			n, err := scanDelete(ctx, node, pattern)
+			deleted.Add(n)
+			return err
+		})
+		return deleted.Load(), err
+	}
+	return scanDelete(ctx, c.client, pattern)
+}
+
+// setWithTagKeys stores value and adds key to each tag set in one pipeline.
+func (c *RedisCache) setWithTagKeys(ctx context.Context, key string, value any, expiration time.Duration, tagKeys []string) error {
+	// Round up so that a tag set never expires before its member.
+	ttl := expiration.Milliseconds()
+	if time.Duration(ttl)*time.Millisecond < expiration {
+		ttl++
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, value, expiration)
+		for _, tagKey := range tagKeys {
+			addTagScript.Eval(ctx, pipe, []string{tagKey}, key, ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// invalidateTagKeys deletes the keys held by the given tag sets and returns them.
+// If the keys cannot be deleted, the popped members are put back so that the
+// invalidation can be retried.
+func (c *RedisCache) invalidateTagKeys(ctx context.Context, tagKeys []string) ([]string, error) {
+	popped := make(map[string][]string, len(tagKeys))
+	var keys []string
+	for _, tagKey := range tagKeys {
+		members, err := popTagScript.Run(ctx, c.client, []string{tagKey}).StringSlice()
+		if err != nil {
+			err = fmt.Errorf("failed to invalidate tag set %s: %w", tagKey, err)
+			return nil, errors.Join(err, c.restoreTagKeys(ctx, popped))
+		}
+		popped[tagKey] = members
+		keys = append(keys, members...)
+	}
+
+	if err := c.MDelete(ctx, keys...); err != nil {
+		err = fmt.Errorf("failed to delete tagged keys: %w", err)
+		return nil, errors.Join(err, c.restoreTagKeys(ctx, popped))
+	}
+	return keys, nil
+}
+
+// restoreTagKeys adds popped members back to their tag sets. It ignores ctx's
+// cancellation, which is a common reason for the invalidation to have failed.
+func (c *RedisCache) restoreTagKeys(ctx context.Context, popped map[string][]string) error {
+	ctx = context.WithoutCancel(ctx)
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for tagKey, members := range popped {
+			if len(members) == 0 {
+				continue
+			}
+			args := make([]any, len(members))
+			for i, member := range members {
+				args[i] = member
+			}
+			pipe.SAdd(ctx, tagKey, args...)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore tag sets: %w", err)
+	}
+	return nil
+}
+
+// tagKeys returns the Redis keys of the sets holding the given tags.
+func tagKeys(tags []string) []string {
+	return prefixKeys(tagKeyPrefix, tags)
+}
+
+// scanDelete incrementally scans a single node and unlinks matching keys.
+// Keys are unlinked individually in a pipeline because a multi-key UNLINK
+// fails when keys hash to different cluster slots.
+func scanDelete(ctx context.Context, client redis.Cmdable, pattern string) (int64, error) {
+	var deleted int64
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, scanCount).Result()
+		if err != nil {
+			return deleted, fmt.Errorf("failed to scan keys matching %s: %w", pattern, err)
+		}
+
+		if len(keys) > 0 {
+			cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+				for _, key := range keys {
+					pipe.Unlink(ctx, key)
+				}
+				return nil
+			})
+			if err != nil {
+				return deleted, fmt.Errorf("failed to delete keys matching %s: %w", pattern, err)
+			}
+			for _, cmd := range cmds {
+				deleted += cmd.(*redis.IntCmd).Val()
+			}
+		}
+
+		if next == 0 {
+			return deleted, nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,78 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/redis"
+)
+
+// failingDeletes fails every pipeline that deletes keys while enabled
+type failingDeletes struct {
+	enabled bool
+}
+
+func (h *failingDeletes) DialHook(next goredis.DialHook) goredis.DialHook { return next }
+
+func (h *failingDeletes) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook { return next }
+
+func (h *failingDeletes) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		for _, cmd := range cmds {
+			if h.enabled && cmd.Name() == "del" {
+				return errors.New("connection reset")
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// TestTagSets verifies tag set placement under the namespace and their expiration
+func TestTagSets(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newMiniredis(t)
+	c := redis.NewNamespacedCache(redis.NewRedisCache(client), "app:")
+
+	require.NoError(t, c.SetWithTags(ctx, "user:1", "a", time.Minute, "users"))
+	assert.True(t, mr.Exists("app:_tag:users"), "tag set must live under the namespace")
+	assert.Equal(t, time.Minute, mr.TTL("app:_tag:users"))
+
+	require.NoError(t, c.SetBytesWithTags(ctx, "user:2", []byte("b"), time.Hour, "users"))
+	assert.Equal(t, time.Hour, mr.TTL("app:_tag:users"), "tag set must outlive its longest-lived member")
+
+	require.NoError(t, c.SetWithTags(ctx, "user:3", "c", time.Second, "users"))
+	assert.Equal(t, time.Hour, mr.TTL("app:_tag:users"), "a shorter-lived member must not shorten the set")
+
+	require.NoError(t, c.SetWithTags(ctx, "user:4", "d", 0, "users"))
+	assert.Equal(t, time.Duration(0), mr.TTL("app:_tag:users"), "a member without expiration must persist the set")
+
+	require.NoError(t, c.InvalidateTag(ctx, "users"))
+	assert.Equal(t, []string{}, mr.Keys())
+}
+
+// TestInvalidateTagRestoresOnFailure verifies that popped members are put back when deletion fails
+func TestInvalidateTagRestoresOnFailure(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newMiniredis(t)
+	hook := &failingDeletes{}
+	client.AddHook(hook)
+	c := redis.NewRedisCache(client)
+
+	require.NoError(t, c.SetWithTags(ctx, "user:1", "a", time.Minute, "users"))
+	require.NoError(t, c.SetWithTags(ctx, "user:2", "b", time.Minute, "users"))
+
+	hook.enabled = true
+	require.Error(t, c.InvalidateTag(ctx, "users"))
+	members, err := mr.Members("_tag:users")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user:1", "user:2"}, members)
+
+	hook.enabled = false
+	require.NoError(t, c.InvalidateTag(ctx, "users"))
+	assert.Equal(t, []string{}, mr.Keys())
+}
@@ -0,0 +1,465 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/memorycache"
+	"go.uber.org/fx"
+)
+
+// TieredConfig holds the two-tier cache configuration.
+// It is loaded from the "cache" key so that it can be tuned independently of
+// the Redis connection settings.
+type TieredConfig struct {
+	Enabled bool             `mapstructure:"tiered"`               // Layer an in-process L1 in front of Redis
+	Channel string           `mapstructure:"invalidation_channel"` // Pub/sub channel used for L1 invalidation
+	L1      LocalTierConfig  `mapstructure:"l1"`
+	L2      RemoteTierConfig `mapstructure:"l2"`
+}
+
+// LocalTierConfig configures the in-process L1 tier.
+type LocalTierConfig struct {
+	TTL        time.Duration `mapstructure:"ttl"`         // Maximum lifetime of an L1 entry
+	MaxEntries int           `mapstructure:"max_entries"` // Maximum number of L1 entries (0 = unbounded)
+}
+
+// RemoteTierConfig configures the Redis L2 tier.
+type RemoteTierConfig struct {
+	TTL time.Duration `mapstructure:"ttl"` // Maximum lifetime of an L2 entry (0 = caller's expiration)
+}
+
+// NewTieredConfig creates a new two-tier cache configuration from Viper.
+func NewTieredConfig(v *viper.Viper) *TieredConfig {
+	cfg := &TieredConfig{
+		Enabled: false,
+		Channel: "things-kit:cache:invalidate",
+		L1: LocalTierConfig{
+			TTL:        time.Minute,
+			MaxEntries: 10000,
+		},
+	}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("cache", cfg)
+	}
+
+	return cfg
+}
+
+// invalidation is the message published when keys change on one instance.
+type invalidation struct {
+	Origin   string   `json:"origin"`
+	Keys     []string `json:"keys,omitempty"`
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// TieredCache implements the cache.BatchCache and cache.TaggedCache interfaces
+// with an in-process L1 in front of Redis (L2). Reads are served from L1 when possible; writes go to both
+// tiers and publish an invalidation so that other instances evict their L1 copy.
+// An L1 entry filled from Redis never outlives the remaining TTL of its L2 copy.
+//
+// Invalidation is best-effort: if a publish fails, other instances may serve a
+// stale value for at most the L1 TTL.
+type TieredCache struct {
+	local    *memorycache.MemoryCache
+	remote   *RedisCache
+	client   redis.UniversalClient
+	config   *TieredConfig
+	logger   log.Logger
+	instance string
+	pubsub   *redis.PubSub
+}
+
+// NewTieredCache creates a two-tier cache on top of the given Redis cache.
+// The invalidation subscription is started and stopped with the Fx lifecycle.
+func NewTieredCache(lc fx.Lifecycle, cfg *TieredConfig, client redis.UniversalClient, remote *RedisCache, logger log.Logger) *TieredCache {
+	c := &TieredCache{
+		local:    memorycache.New(cfg.L1.MaxEntries),
+		remote:   remote,
+		client:   client,
+		config:   cfg,
+		logger:   logger,
+		instance: randomHex(8),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return c.subscribe(ctx)
+		},
+		OnStop: func(ctx context.Context) error {
+			c.local.Flush()
+			if c.pubsub == nil {
+				return nil
+			}
+			return c.pubsub.Close()
+		},
+	})
+
+	return c
+}
+
+// Get retrieves the value for the given key, populating L1 on an L2 hit.
+func (c *TieredCache) Get(ctx context.Context, key string) (string, error) {
+	if value, err := c.local.Get(ctx, key); err == nil {
+		return value, nil
+	}
+
+	value, remaining, err := c.remote.getWithTTL(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if ttl, ok := c.fillTTL(remaining); ok {
+		_ = c.local.Set(ctx, key, string(value), ttl)
+	}
+	return string(value), nil
+}
+
+// Set stores a value in both tiers and invalidates other instances.
+func (c *TieredCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	if err := c.remote.Set(ctx, key, value, capTTL(expiration, c.config.L2.TTL)); err != nil {
+		return err
+	}
+	_ = c.local.Set(ctx, key, value, capTTL(expiration, c.config.L1.TTL))
+	c.publish(ctx, invalidation{Keys: []string{key}})
+	return nil
+}
+
+// Delete removes the key from both tiers and invalidates other instances.
+func (c *TieredCache) Delete(ctx context.Context, key string) error {
+	_ = c.local.Delete(ctx, key)
+	if err := c.remote.Delete(ctx, key); err != nil {
+		return err
+	}
+	c.publish(ctx, invalidation{Keys: []string{key}})
+	return nil
+}
+
+// Exists checks if a key exists in either tier.
+func (c *TieredCache) Exists(ctx context.Context, key string) (bool, error) {
+	if ok, _ := c.local.Exists(ctx, key); ok {
+		return true, nil
+	}
+	return c.remote.Exists(ctx, key)
+}
+
+// GetBytes retrieves the raw byte value for the given key, populating L1 on an L2 hit.
+func (c *TieredCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	if value, err := c.local.GetBytes(ctx, key); err == nil {
+		return value, nil
+	}
+
+	value, remaining, err := c.remote.getWithTTL(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if ttl, ok := c.fillTTL(remaining); ok {
+		_ = c.local.SetBytes(ctx, key, value, ttl)
+	}
+	return value, nil
+}
+
+// SetBytes stores a raw byte value in both tiers and invalidates other instances.
+func (c *TieredCache) SetBytes(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	if err := c.remote.SetBytes(ctx, key, value, capTTL(expiration, c.config.L2.TTL)); err != nil {
+		return err
+	}
+	_ = c.local.SetBytes(ctx, key, value, capTTL(expiration, c.config.L1.TTL))
+	c.publish(ctx, invalidation{Keys: []string{key}})
+	return nil
+}
+
+// Expire sets a timeout on a key in Redis and drops any L1 copies so that
+// they are refreshed with the new expiration.
+func (c *TieredCache) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	ok, err := c.remote.Expire(ctx, key, expiration)
+	if err != nil {
+		return false, err
+	}
+	_ = c.local.Delete(ctx, key)
+	c.publish(ctx, invalidation{Keys: []string{key}})
+	return ok, nil
+}
+
+// TTL returns the remaining time to live of a key as recorded in Redis.
+func (c *TieredCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.remote.TTL(ctx, key)
+}
+
+// Ping tests connectivity to Redis.
+func (c *TieredCache) Ping(ctx context.Context) error {
+	return c.remote.Ping(ctx)
+}
+
+// Close clears L1 and closes the connection to Redis.
+func (c *TieredCache) Close() error {
+	c.local.Flush()
+	return c.remote.Close()
+}
+
+// SetWithTags stores a value in both tiers, tags it in Redis, and invalidates other instances.
+func (c *TieredCache) SetWithTags(ctx context.Context, key string, value string, expiration time.Duration, tags ...string) error {
+	return c.setWithTagKeys(ctx, key, value, expiration, tagKeys(tags))
+}
+
+// SetBytesWithTags stores a raw byte value in both tiers, tags it in Redis, and invalidates other instances.
+func (c *TieredCache) SetBytesWithTags(ctx context.Context, key string, value []byte, expiration time.Duration, tags ...string) error {
+	return c.setWithTagKeys(ctx, key, value, expiration, tagKeys(tags))
+}
+
+// InvalidateTag deletes every key carrying any of the given tags from both
+// tiers and invalidates those keys on other instances.
+func (c *TieredCache) InvalidateTag(ctx context.Context, tags ...string) error {
+	_, err := c.invalidateTagKeys(ctx, tagKeys(tags))
+	return err
+}
+
+// setWithTagKeys stores a string or byte value in both tiers, adds it to the
+// given tag sets in Redis, and invalidates other instances.
+func (c *TieredCache) setWithTagKeys(ctx context.Context, key string, value any, expiration time.Duration, tagKeys []string) error {
+	if err := c.remote.setWithTagKeys(ctx, key, value, capTTL(expiration, c.config.L2.TTL), tagKeys); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case string:
+		_ = c.local.Set(ctx, key, v, capTTL(expiration, c.config.L1.TTL))
+	case []byte:
+		_ = c.local.SetBytes(ctx, key, v, capTTL(expiration, c.config.L1.TTL))
+	}
+	c.publish(ctx, invalidation{Keys: []string{key}})
+	return nil
+}
+
+// invalidateTagKeys deletes the keys held by the given tag sets from both
+// tiers and invalidates them on other instances.
+func (c *TieredCache) invalidateTagKeys(ctx context.Context, tagKeys []string) ([]string, error) {
+	keys, err := c.remote.invalidateTagKeys(ctx, tagKeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > 0 {
+		_ = c.local.MDelete(ctx, keys...)
+		c.publish(ctx, invalidation{Keys: keys})
+	}
+	return keys, nil
+}
+
+// DeletePattern deletes matching keys from both tiers and invalidates the
+// pattern on other instances.
+func (c *TieredCache) DeletePattern(ctx context.Context, pattern string) (int64, error) {
+	deleted, err := c.remote.DeletePattern(ctx, pattern)
+	if err != nil {
+		return deleted, err
+	}
+	_, _ = c.local.DeletePattern(ctx, pattern)
+	c.publish(ctx, invalidation{Patterns: []string{pattern}})
+	return deleted, nil
+}
+
+// MGet retrieves multiple values at once. Keys missing from L1 are read from
+// Redis in one round trip and populate L1.
+func (c *TieredCache) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	result, _ := c.local.MGet(ctx, keys...)
+
+	var missing []string
+	for _, key := range keys {
+		if _, ok := result[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	values, remaining, err := c.remote.mgetWithTTL(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		result[key] = string(value)
+		if ttl, ok := c.fillTTL(remaining[key]); ok {
+			_ = c.local.SetBytes(ctx, key, value, ttl)
+		}
+	}
+	return result, nil
+}
+
+// MSet stores multiple key-value pairs in both tiers and invalidates other instances.
+func (c *TieredCache) MSet(ctx context.Context, pairs map[string]string, expiration time.Duration) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+	if err := c.remote.MSet(ctx, pairs, capTTL(expiration, c.config.L2.TTL)); err != nil {
+		return err
+	}
+	_ = c.local.MSet(ctx, pairs, capTTL(expiration, c.config.L1.TTL))
+
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	c.publish(ctx, invalidation{Keys: keys})
+	return nil
+}
+
+// MDelete removes multiple keys from both tiers and invalidates other instances.
+func (c *TieredCache) MDelete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_ = c.local.MDelete(ctx, keys...)
+	if err := c.remote.MDelete(ctx, keys...); err != nil {
+		return err
+	}
+	c.publish(ctx, invalidation{Keys: keys})
+	return nil
+}
+
+// subscribe starts listening for invalidations published by other instances.
+func (c *TieredCache) subscribe(ctx context.Context) error {
+	pubsub := c.client.Subscribe(ctx, c.config.Channel)
+
+	// Wait for the subscription to be confirmed so no invalidation is missed
+	// once the application has started.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return fmt.Errorf("failed to subscribe to cache invalidation channel %s: %w", c.config.Channel, err)
+	}
+	c.pubsub = pubsub
+
+	go func() {
+		for msg := range pubsub.Channel() {
+			c.handleInvalidation(msg.Payload)
+		}
+	}()
+
+	return nil
+}
+
+// handleInvalidation evicts the L1 entries named in an invalidation message.
+func (c *TieredCache) handleInvalidation(payload string) {
+	var inv invalidation
+	if err := json.Unmarshal([]byte(payload), &inv); err != nil {
+		c.logger.Error("Invalid cache invalidation message", err, log.Field{Key: "channel", Value: c.config.Channel})
+		return
+	}
+	if inv.Origin == c.instance {
+		return
+	}
+	_ = c.local.MDelete(context.Background(), inv.Keys...)
+	for _, pattern := range inv.Patterns {
+		_, _ = c.local.DeletePattern(context.Background(), pattern)
+	}
+}
+
+// publish notifies other instances that the given keys or patterns have changed.
+func (c *TieredCache) publish(ctx context.Context, inv invalidation) {
+	inv.Origin = c.instance
+	payload, err := json.Marshal(inv)
+	if err == nil {
+		err = c.client.Publish(ctx, c.config.Channel, payload).Err()
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		c.logger.WarnC(ctx, "Failed to publish cache invalidation", err,
+			log.Field{Key: "channel", Value: c.config.Channel},
+			log.Field{Key: "keys", Value: inv.Keys},
+			log.Field{Key: "patterns", Value: inv.Patterns},
+		)
+	}
+}
+
+// fillTTL returns the lifetime of an L1 entry filled from L2, given the
+// remaining Redis TTL as reported by PTTL. The entry never outlives its L2
+// copy; false means the key is already gone from Redis and must not be cached.
+func (c *TieredCache) fillTTL(remaining time.Duration) (time.Duration, bool) {
+	switch {
+	case remaining == -1: // no expiration in Redis
+		return c.config.L1.TTL, true
+	case remaining <= 0:
+		return 0, false
+	}
+	return capTTL(remaining, c.config.L1.TTL), true
+}
+
+// getWithTTL reads a value and its remaining lifetime from Redis in a single
+// round trip.
+func (c *RedisCache) getWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	var (
+		get  *redis.StringCmd
+		pttl *redis.DurationCmd
+	)
+	_, _ = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+
+	value, err := get.Bytes()
+	if err != nil {
+		return nil, 0, translateError(err)
+	}
+	remaining, err := pttl.Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	return value, remaining, nil
+}
+
+// mgetWithTTL reads the values of keys and their remaining lifetimes from
+// Redis in a single round trip. Missing keys are left out of both maps.
+func (c *RedisCache) mgetWithTTL(ctx context.Context, keys []string) (map[string][]byte, map[string]time.Duration, error) {
+	gets := make([]*redis.StringCmd, len(keys))
+	pttls := make([]*redis.DurationCmd, len(keys))
+	_, _ = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			gets[i] = pipe.Get(ctx, key)
+			pttls[i] = pipe.PTTL(ctx, key)
+		}
+		return nil
+	})
+
+	values := make(map[string][]byte, len(keys))
+	remaining := make(map[string]time.Duration, len(keys))
+	for i, key := range keys {
+		value, err := gets[i].Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		ttl, err := pttls[i].Result()
+		if err != nil {
+			return nil, nil, err
+		}
+		values[key] = value
+		remaining[key] = ttl
+	}
+	return values, remaining, nil
+}
+
+// capTTL bounds an expiration by a tier limit.
+// A zero expiration means "no expiration" and a zero limit means "no limit".
+func capTTL(expiration, limit time.Duration) time.Duration {
+	if limit > 0 && (expiration <= 0 || expiration > limit) {
+		return limit
+	}
+	return expiration
+}
+
+// randomHex returns n random bytes encoded as hex.
+// It is used for instance identifiers and lock owner tokens.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,212 @@
+package redis_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/cache"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/redis"
+	"go.uber.org/fx/fxtest"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+// newMiniredis starts an in-memory Redis server and returns a client connected to it
+func newMiniredis(t *testing.T) (*miniredis.Miniredis, *goredis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return mr, client
+}
+
+// newTieredCache starts a two-tier cache on client whose lifecycle ends with the test
+func newTieredCache(t *testing.T, client goredis.UniversalClient) *redis.TieredCache {
+	t.Helper()
+	cfg := redis.NewTieredConfig(nil)
+	lc := fxtest.NewLifecycle(t)
+	c := redis.NewTieredCache(lc, cfg, client, redis.NewRedisCache(client), nopLogger{})
+	lc.RequireStart()
+	t.Cleanup(lc.RequireStop)
+	return c
+}
+
+// TestTieredCacheReads verifies L1 hits, L2 fills and that L1 never outlives the L2 copy
+func TestTieredCacheReads(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newMiniredis(t)
+	c := newTieredCache(t, client)
+
+	t.Run("L1 hit", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "hit", "v1", time.Hour))
+		require.NoError(t, mr.Set("hit", "changed behind the cache"))
+
+		value, err := c.Get(ctx, "hit")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", value)
+	})
+
+	t.Run("L2 fill", func(t *testing.T) {
+		require.NoError(t, mr.Set("fill", "v1"))
+
+		value, err := c.GetBytes(ctx, "fill")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v1"), value)
+
+		require.NoError(t, mr.Set("fill", "changed behind the cache"))
+		value, err = c.GetBytes(ctx, "fill")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v1"), value, "second read must be served from L1")
+	})
+
+	t.Run("L1 capped at remaining L2 TTL", func(t *testing.T) {
+		require.NoError(t, mr.Set("short", "v1"))
+		mr.SetTTL("short", 50*time.Millisecond)
+
+		value, err := c.Get(ctx, "short")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", value)
+
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, mr.Set("short", "v2"))
+		value, err = c.Get(ctx, "short")
+		require.NoError(t, err)
+		assert.Equal(t, "v2", value, "L1 entry must expire with the L2 copy")
+	})
+}
+
+// TestTieredCacheInvalidation verifies that writes evict other instances' L1 but not the writer's own
+func TestTieredCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newMiniredis(t)
+	a := newTieredCache(t, client)
+	b := newTieredCache(t, client)
+
+	t.Run("from another instance", func(t *testing.T) {
+		require.NoError(t, mr.Set("user:1", "v1"))
+		value, err := a.Get(ctx, "user:1")
+		require.NoError(t, err)
+		require.Equal(t, "v1", value)
+
+		require.NoError(t, b.Set(ctx, "user:1", "v2", 0))
+		assert.Eventually(t, func() bool {
+			value, err := a.Get(ctx, "user:1")
+			return err == nil && value == "v2"
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("own origin ignored", func(t *testing.T) {
+		require.NoError(t, mr.Set("own", "v1"))
+		require.NoError(t, mr.Set("marker", "v1"))
+		for _, key := range []string{"own", "marker"} {
+			_, err := a.Get(ctx, key)
+			require.NoError(t, err)
+		}
+		require.NoError(t, mr.Set("own", "v2"))
+		require.NoError(t, mr.Set("marker", "v2"))
+
+		publish := func(origin, key string) {
+			payload, err := json.Marshal(map[string]any{"origin": origin, "keys": []string{key}})
+			require.NoError(t, err)
+			require.NoError(t, client.Publish(ctx, "things-kit:cache:invalidate", payload).Err())
+		}
+		publish(redis.TieredInstance(a), "own")
+		publish("another-instance", "marker")
+
+		// Messages are delivered in order, so once the marker is evicted the
+		// message carrying a's own origin has been handled as well.
+		assert.Eventually(t, func() bool {
+			value, err := a.Get(ctx, "marker")
+			return err == nil && value == "v2"
+		}, time.Second, 10*time.Millisecond)
+
+		value, err := a.Get(ctx, "own")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", value)
+	})
+}
+
+// TestTieredCacheBatch verifies that batch reads use both tiers and batch writes invalidate other instances
+func TestTieredCacheBatch(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newMiniredis(t)
+	a := newTieredCache(t, client)
+	b := newTieredCache(t, client)
+	var _ cache.BatchCache = a
+
+	t.Run("MGet", func(t *testing.T) {
+		require.NoError(t, a.Set(ctx, "local", "v1", time.Hour))
+		require.NoError(t, mr.Set("local", "changed behind the cache"))
+		require.NoError(t, mr.Set("remote", "v1"))
+
+		values, err := a.MGet(ctx, "local", "remote", "missing")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"local": "v1", "remote": "v1"}, values)
+
+		require.NoError(t, mr.Set("remote", "changed behind the cache"))
+		values, err = a.MGet(ctx, "remote")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", values["remote"], "second read must be served from L1")
+	})
+
+	t.Run("MSet and MDelete", func(t *testing.T) {
+		require.NoError(t, mr.Set("batch:1", "v1"))
+		require.NoError(t, mr.Set("batch:2", "v1"))
+		values, err := a.MGet(ctx, "batch:1", "batch:2")
+		require.NoError(t, err)
+		require.Len(t, values, 2)
+
+		require.NoError(t, b.MSet(ctx, map[string]string{"batch:1": "v2", "batch:2": "v2"}, time.Hour))
+		assert.Equal(t, time.Hour, mr.TTL("batch:1"))
+		assert.Eventually(t, func() bool {
+			values, err := a.MGet(ctx, "batch:1", "batch:2")
+			return err == nil && values["batch:1"] == "v2" && values["batch:2"] == "v2"
+		}, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, b.MDelete(ctx, "batch:1", "batch:2"))
+		assert.False(t, mr.Exists("batch:1"))
+		assert.Eventually(t, func() bool {
+			values, err := a.MGet(ctx, "batch:1", "batch:2")
+			return err == nil && len(values) == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+// TestCapTTL verifies how expirations are bounded by tier limits
+func TestCapTTL(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiration time.Duration
+		limit      time.Duration
+		want       time.Duration
+	}{
+		{name: "no limit", expiration: time.Hour, limit: 0, want: time.Hour},
+		{name: "no expiration and no limit", expiration: 0, limit: 0, want: 0},
+		{name: "no expiration", expiration: 0, limit: time.Minute, want: time.Minute},
+		{name: "above limit", expiration: time.Hour, limit: time.Minute, want: time.Minute},
+		{name: "below limit", expiration: time.Second, limit: time.Minute, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, redis.CapTTL(tt.expiration, tt.limit))
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// Package sharedport serves the gRPC and HTTP servers on a single port.
+// Incoming connections are routed by protocol: HTTP/2 requests with an
+// application/grpc content type go to the grpc module, everything else to
+// the HTTP server. Both servers keep their own lifecycle.
+package sharedport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/soheilhy/cmux"
+	"github.com/spf13/viper"
+	"github.com/things-kit/module/log"
+	"go.uber.org/fx"
+)
+
+// Module provides the shared listeners to the grpc and httpgin modules.
+// With shared_port.enabled unset, both servers keep listening on their own ports.
+var Module = fx.Module("sharedport",
+	fx.Provide(
+		NewConfig,
+		NewListeners,
+		fx.Annotate(
+			func(l *Listeners) net.Listener { return l.GRPC() },
+			fx.ResultTags(`name:"grpc.listener"`),
+		),
+		fx.Annotate(
+			func(l *Listeners) net.Listener { return l.HTTP() },
+			fx.ResultTags(`name:"http.listener"`),
+		),
+	),
+)
+
+// Config holds the shared port configuration.
+type Config struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Host        string        `mapstructure:"host"` // Empty = all interfaces
+	Port        int           `mapstructure:"port"`
+	ReadTimeout time.Duration `mapstructure:"read_timeout"` // Time allowed for a new connection to reveal its protocol
+}
+
+// NewConfig creates the shared port configuration from Viper.
+func NewConfig(v *viper.Viper) *Config {
+	cfg := &Config{
+		Port:        8080,
+		ReadTimeout: 5 * time.Second,
+	}
+
+	// Load configuration from viper
+	if v != nil {
+		_ = v.UnmarshalKey("shared_port", cfg)
+	}
+
+	return cfg
+}
+
+// Listeners splits one port into a gRPC and an HTTP listener.
+// The port is opened when the application starts and closed when it stops,
+// after both servers have stopped.
+type Listeners struct {
+	cfg    *Config
+	logger log.Logger
+	addr   string
+	grpc   *listener
+	http   *listener
+
+	mu   sync.Mutex
+	root net.Listener
+}
+
+// NewListeners creates the shared listeners, or returns nil when shared_port is disabled.
+func NewListeners(lc fx.Lifecycle, cfg *Config, logger log.Logger) *Listeners {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	l := &Listeners{
+		cfg:    cfg,
+		logger: logger,
+		addr:   net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+	}
+	l.grpc = newListener(l)
+	l.http = newListener(l)
+
+	// Registered before the servers' hooks: starts first and stops last
+	lc.Append(fx.Hook{
+		OnStart: l.start,
+		OnStop: func(ctx context.Context) error {
+			return l.stop()
+		},
+	})
+
+	return l
+}
+
+// GRPC returns the listener receiving gRPC connections (nil when disabled).
+func (l *Listeners) GRPC() net.Listener {
+	if l == nil {
+		return nil
+	}
+	return l.grpc
+}
+
+// HTTP returns the listener receiving all other connections (nil when disabled).
+func (l *Listeners) HTTP() net.Listener {
+	if l == nil {
+		return nil
+	}
+	return l.http
+}
+
+// start opens the port and starts routing connections.
+func (l *Listeners) start(ctx context.Context) error {
+	root, err := net.Listen("tcp", l.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", l.addr, err)
+	}
+
+	l.mu.Lock()
+	l.root = root
+	l.mu.Unlock()
+
+	m := cmux.New(root)
+	m.SetReadTimeout(l.cfg.ReadTimeout)
+	// gRPC clients wait for the server's SETTINGS frame before sending headers
+	grpcL := m.MatchWithWriters(cmux.HTTP2MatchHeaderFieldPrefixSendSettings("content-type", "application/grpc"))
+	httpL := m.Match(cmux.Any())
+
+	go l.grpc.pump(grpcL)
+	go l.http.pump(httpL)
+	go func() {
+		if err := m.Serve(); err != nil && !errors.Is(err, net.ErrClosed) {
+			l.logger.Error("Shared port error", err, log.Field{Key: "address", Value: l.addr})
+		}
+	}()
+
+	l.logger.Info("Serving gRPC and HTTP on a shared port", log.Field{Key: "address", Value: root.Addr().String()})
+	return nil
+}
+
+// stop closes the port.
+func (l *Listeners) stop() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.root == nil {
+		return nil
+	}
+	err := l.root.Close()
+	l.root = nil
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		return fmt.Errorf("failed to close shared port: %w", err)
+	}
+	return nil
+}
+
+// rootAddr returns the address of the open port, or the configured one before start.
+func (l *Listeners) rootAddr() net.Addr {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.root != nil {
+		return l.root.Addr()
+	}
+	return addr(l.addr)
+}
+
+// listener receives the connections routed to one server. Closing it stops
+// delivery to that server only; the port stays open for the other one.
+type listener struct {
+	parent *Listeners
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newListener(parent *Listeners) *listener {
+	return &listener{
+		parent: parent,
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+// pump forwards connections matched by cmux until the port is closed.
+func (l *listener) pump(matched net.Listener) {
+	for {
+		conn, err := matched.Accept()
+		if err != nil {
+			l.Close()
+			return
+		}
+		select {
+		case l.conns <- conn:
+		case <-l.closed:
+			_ = conn.Close()
+		}
+	}
+}
+
+// Accept implements net.Listener.
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close implements net.Listener.
+func (l *listener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+// Addr implements net.Listener.
+func (l *listener) Addr() net.Addr {
+	return l.parent.rootAddr()
+}
+
+// addr is a configured TCP address.
+type addr string
+
+func (a addr) Network() string { return "tcp" }
+func (a addr) String() string  { return string(a) }
@@ -0,0 +1,121 @@
+package sharedport_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpcmodule "github.com/things-kit/module/grpc"
+	"github.com/things-kit/module/httpgin"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/sharedport"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// countingLogger discards log entries and counts errors
+type countingLogger struct{ errors *atomic.Int32 }
+
+func (countingLogger) Info(string, ...log.Field)                   {}
+func (l countingLogger) Error(string, error, ...log.Field)         { l.errors.Add(1) }
+func (countingLogger) Debug(string, ...log.Field)                  {}
+func (countingLogger) Warn(string, ...log.Field)                   {}
+func (countingLogger) InfoC(context.Context, string, ...log.Field) {}
+func (l countingLogger) ErrorC(context.Context, string, error, ...log.Field) {
+	l.errors.Add(1)
+}
+func (countingLogger) DebugC(context.Context, string, ...log.Field)       {}
+func (countingLogger) WarnC(context.Context, string, error, ...log.Field) {}
+
+// pingHandler serves GET /ping
+type pingHandler struct{}
+
+func (pingHandler) RegisterRoutes(engine *gin.Engine) {
+	engine.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+}
+
+// freePort returns a TCP port that is currently free
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// TestSharedPort verifies that gRPC and HTTP are served on one port and stop cleanly
+func TestSharedPort(t *testing.T) {
+	port := freePort(t)
+	v := viper.New()
+	v.Set("shared_port.enabled", true)
+	v.Set("shared_port.host", "127.0.0.1")
+	v.Set("shared_port.port", port)
+	v.Set("grpc.port", freePort(t)) // Unused while sharing
+	v.Set("http.port", freePort(t)) // Unused while sharing
+
+	var errors atomic.Int32
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return countingLogger{errors: &errors} }),
+		sharedport.Module,
+		grpcmodule.Module,
+		httpgin.Module,
+		httpgin.AsGinHandler(func() pingHandler { return pingHandler{} }),
+	)
+	app.RequireStart()
+
+	addr := "127.0.0.1:" + strconv.Itoa(port)
+
+	resp, err := http.Get("http://" + addr + "/ping")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "pong", string(body))
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	check, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check.Status)
+
+	app.RequireStop()
+	assert.Zero(t, errors.Load(), "servers must stop without errors")
+
+	_, err = net.DialTimeout("tcp", addr, 100*time.Millisecond)
+	assert.Error(t, err, "the shared port must be closed")
+}
+
+// TestSharedPortDisabled verifies that the servers keep their own listeners when disabled
+func TestSharedPortDisabled(t *testing.T) {
+	var listeners struct {
+		fx.In
+		GRPC net.Listener `name:"grpc.listener"`
+		HTTP net.Listener `name:"http.listener"`
+	}
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(viper.New()),
+		fx.Provide(func() log.Logger { return countingLogger{errors: new(atomic.Int32)} }),
+		sharedport.Module,
+		fx.Populate(&listeners),
+	)
+	defer app.RequireStart().RequireStop()
+
+	assert.Nil(t, listeners.GRPC)
+	assert.Nil(t, listeners.HTTP)
+}
@@ -32,18 +32,19 @@ func (l *testLogger) Warn(msg string, fields ...log.Field) {
 }
 
 func (l *testLogger) InfoC(ctx context.Context, msg string, fields ...log.Field) {
-	l.Info(msg, fields...)
+	l.Info(msg, append(fields, log.FieldsFrom(ctx)...)...)
 }
 
 func (l *testLogger) ErrorC(ctx context.Context, msg string, err error, fields ...log.Field) {
-	l.Error(msg, err, fields...)
+	l.Error(msg, err, append(fields, log.FieldsFrom(ctx)...)...)
 }
 
 func (l *testLogger) DebugC(ctx context.Context, msg string, fields ...log.Field) {
-	l.Debug(msg, fields...)
+	l.Debug(msg, append(fields, log.FieldsFrom(ctx)...)...)
 }
 
 func (l *testLogger) WarnC(ctx context.Context, msg string, err error, fields ...log.Field) {
+	fields = append(fields, log.FieldsFrom(ctx)...)
 	if err != nil {
 		l.t.Logf("[WARN] %s: %v %v", msg, err, fields)
 	} else {
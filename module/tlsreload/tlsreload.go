@@ -0,0 +1,246 @@
+// Package tlsreload serves TLS certificates from disk, reloading them when the
+// files change. It holds the TLS configuration shared by the grpc and httpgin
+// servers; each builds its transport on top of a Reloader.
+package tlsreload
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/things-kit/module/log"
+)
+
+// Client authentication modes for Config.ClientAuth.
+const (
+	ClientAuthNone             = "none"               // Do not request client certificates
+	ClientAuthRequest          = "request"            // Request but do not require or verify
+	ClientAuthVerifyIfGiven    = "verify_if_given"    // Verify client certificates when presented
+	ClientAuthRequireAndVerify = "require_and_verify" // Mutual TLS
+)
+
+// Config holds the TLS settings of a server.
+type Config struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	CertFile       string        `mapstructure:"cert_file"`       // PEM server certificate chain
+	KeyFile        string        `mapstructure:"key_file"`        // PEM server private key
+	ClientCAFile   string        `mapstructure:"client_ca_file"`  // PEM bundle used to verify client certificates
+	ClientAuth     string        `mapstructure:"client_auth"`     // none, request, verify_if_given, require_and_verify
+	MinVersion     string        `mapstructure:"min_version"`     // "1.2" or "1.3"
+	ReloadInterval time.Duration `mapstructure:"reload_interval"` // How often files are checked for changes (0 = never)
+}
+
+// ClientIdentity is the identity of a client that presented a verified certificate.
+type ClientIdentity struct {
+	SPIFFEID    string // URI SAN with the spiffe scheme, if any
+	CommonName  string // Subject common name
+	Certificate *x509.Certificate
+}
+
+// IdentityFromState returns the identity of the client of a TLS connection.
+// It reports false unless the client certificate was verified.
+func IdentityFromState(state tls.ConnectionState) (ClientIdentity, bool) {
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return ClientIdentity{}, false
+	}
+
+	cert := state.VerifiedChains[0][0]
+	id := ClientIdentity{CommonName: cert.Subject.CommonName, Certificate: cert}
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			id.SPIFFEID = uri.String()
+			break
+		}
+	}
+	return id, true
+}
+
+// Reloader serves the certificate and client CAs from disk, reloading them
+// when the files' modification times change. If a reload fails, the previous
+// files stay in use.
+type Reloader struct {
+	key        string // Configuration key, e.g. "grpc.tls"
+	cfg        Config
+	clientAuth tls.ClientAuthType
+	minVersion uint16
+	logger     log.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  []time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewReloader validates cfg and loads the files for the first time. Errors
+// name the settings after key, the configuration key of cfg such as
+// "grpc.tls".
+func NewReloader(key string, cfg Config, logger log.Logger) (*Reloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, fmt.Errorf("%s requires cert_file and key_file", key)
+	}
+
+	r := &Reloader{key: key, cfg: cfg, logger: logger}
+
+	switch strings.ToLower(cfg.MinVersion) {
+	case "", "1.2":
+		r.minVersion = tls.VersionTLS12
+	case "1.3":
+		r.minVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported %s.min_version %q", key, cfg.MinVersion)
+	}
+
+	clientAuth := cfg.ClientAuth
+	if clientAuth == "" {
+		clientAuth = ClientAuthNone
+		if cfg.ClientCAFile != "" {
+			clientAuth = ClientAuthRequireAndVerify
+		}
+	}
+	switch clientAuth {
+	case ClientAuthNone:
+		r.clientAuth = tls.NoClientCert
+	case ClientAuthRequest:
+		r.clientAuth = tls.RequestClientCert
+	case ClientAuthVerifyIfGiven:
+		r.clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequireAndVerify:
+		r.clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unsupported %s.client_auth %q", key, cfg.ClientAuth)
+	}
+	if r.clientAuth >= tls.VerifyClientCertIfGiven && cfg.ClientCAFile == "" {
+		return nil, fmt.Errorf("%s.client_auth %q requires client_ca_file", key, clientAuth)
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// files returns the paths watched for changes.
+func (r *Reloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+// load reads the certificate and client CAs from disk.
+func (r *Reloader) load() error {
+	var modTimes []time.Time
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("failed to read TLS file: %w", err)
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA file %s", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.clientCAs, r.modTimes = &cert, clientCAs, modTimes
+	r.mu.Unlock()
+	return nil
+}
+
+// changed reports whether any file was modified since the last load.
+func (r *Reloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(r.modTimes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// reload reloads the files if they changed, logging the outcome.
+func (r *Reloader) reload() {
+	if !r.changed() {
+		return
+	}
+	if err := r.load(); err != nil {
+		r.logger.Error("Failed to reload TLS certificates, keeping previous ones", err, log.Field{Key: "config", Value: r.key})
+		return
+	}
+	r.logger.Info("Reloaded TLS certificates", log.Field{Key: "config", Value: r.key}, log.Field{Key: "cert_file", Value: r.cfg.CertFile})
+}
+
+// Start polls the files every ReloadInterval until Close is called.
+func (r *Reloader) Start() {
+	if r.cfg.ReloadInterval <= 0 {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.cfg.ReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.reload()
+			}
+		}
+	}()
+}
+
+// Close stops polling. The Reloader can be started again afterwards.
+func (r *Reloader) Close() {
+	if r.stop != nil {
+		close(r.stop)
+		<-r.done
+		r.stop = nil
+	}
+}
+
+// TLSConfig returns a server TLS configuration that uses the current files for
+// each handshake, offering nextProtos with ALPN.
+func (r *Reloader) TLSConfig(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		NextProtos: nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return &tls.Config{
+				Certificates: []tls.Certificate{*r.cert},
+				ClientCAs:    r.clientCAs,
+				ClientAuth:   r.clientAuth,
+				MinVersion:   r.minVersion,
+				NextProtos:   nextProtos,
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,138 @@
+package tlsreload_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/tlsreload"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+// writeCert writes a self-signed certificate with the given serial and SPIFFE ID
+func writeCert(t *testing.T, certFile, keyFile string, serial int64, spiffe string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if spiffe != "" {
+		u, err := url.Parse(spiffe)
+		require.NoError(t, err)
+		tmpl.URIs = []*url.URL{u}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+// TestReloader verifies that handshakes use the files on disk after they change
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, 10, "")
+
+	certs, err := tlsreload.NewReloader("grpc.tls", tlsreload.Config{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ReloadInterval: 10 * time.Millisecond,
+	}, nopLogger{})
+	require.NoError(t, err)
+
+	cfg := certs.TLSConfig("h2")
+	assert.Equal(t, []string{"h2"}, cfg.NextProtos)
+	serial := func() int64 {
+		hello, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{})
+		require.NoError(t, err)
+		cert, err := x509.ParseCertificate(hello.Certificates[0].Certificate[0])
+		require.NoError(t, err)
+		return cert.SerialNumber.Int64()
+	}
+	assert.Equal(t, int64(10), serial())
+
+	certs.Start()
+	defer certs.Close()
+	time.Sleep(10 * time.Millisecond)
+	writeCert(t, certFile, keyFile, 11, "")
+	assert.Eventually(t, func() bool { return serial() == 11 }, 2*time.Second, 10*time.Millisecond)
+
+	// A broken file keeps the previous certificate
+	require.NoError(t, os.WriteFile(certFile, []byte("not a certificate"), 0o600))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(11), serial())
+}
+
+// TestNewReloaderErrors verifies that invalid settings are reported with their key
+func TestNewReloaderErrors(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, 1, "")
+
+	tests := []struct {
+		name string
+		cfg  tlsreload.Config
+		err  string
+	}{
+		{"missing files", tlsreload.Config{}, "http.tls requires cert_file and key_file"},
+		{"min version", tlsreload.Config{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.1"}, `unsupported http.tls.min_version "1.1"`},
+		{"client auth", tlsreload.Config{CertFile: certFile, KeyFile: keyFile, ClientAuth: "always"}, `unsupported http.tls.client_auth "always"`},
+		{"verify without CA", tlsreload.Config{CertFile: certFile, KeyFile: keyFile, ClientAuth: tlsreload.ClientAuthRequireAndVerify}, "requires client_ca_file"},
+		{"unreadable files", tlsreload.Config{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: keyFile}, "failed to read TLS file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tlsreload.NewReloader("http.tls", tt.cfg, nopLogger{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+// TestIdentityFromState verifies the identity of verified client certificates
+func TestIdentityFromState(t *testing.T) {
+	dir := t.TempDir()
+	cert := writeCert(t, filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), 1, "spiffe://example.org/ns/web/sa/frontend")
+
+	_, ok := tlsreload.IdentityFromState(tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}})
+	assert.False(t, ok, "unverified certificates carry no identity")
+
+	id, ok := tlsreload.IdentityFromState(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}})
+	require.True(t, ok)
+	assert.Equal(t, "spiffe://example.org/ns/web/sa/frontend", id.SPIFFEID)
+	assert.Equal(t, "server", id.CommonName)
+	assert.Same(t, cert, id.Certificate)
+}
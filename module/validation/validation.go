@@ -0,0 +1,154 @@
+// Package validation validates requests before they reach handlers.
+// The validationgrpc package runs the application's Validator on every
+// request message of the grpc module and validationgin runs it on request
+// structs bound from httpgin requests; both reject invalid requests
+// with codes.InvalidArgument (HTTP 400) and a google.rpc.BadRequest listing
+// the field violations.
+package validation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/things-kit/module/errmap"
+	"go.uber.org/fx"
+)
+
+// Module provides the default Validator, which runs the Validate methods
+// generated by protoc-gen-validate or written by hand. validationgrpc.Module
+// and validationgin.Module apply it.
+var Module = fx.Module("validation",
+	fx.Provide(
+		fx.Annotate(
+			NewMethodValidator,
+			fx.As(new(Validator)),
+		),
+	),
+)
+
+// Message is the message of the errors returned for invalid requests.
+const Message = "invalid request"
+
+// Validator validates request values. Implementations return nil for valid
+// requests and values they do not know how to validate.
+//
+// Replace the default Validator to plug in another engine, e.g. protovalidate:
+//
+//	fx.Decorate(func(validation.Validator) validation.Validator {
+//	    return validation.ValidatorFunc(func(ctx context.Context, req any) error {
+//	        msg, ok := req.(proto.Message)
+//	        if !ok {
+//	            return nil
+//	        }
+//	        return toViolations(pv.Validate(msg))
+//	    })
+//	})
+type Validator interface {
+	Validate(ctx context.Context, req any) error
+}
+
+// ValidatorFunc adapts a function to the Validator interface.
+type ValidatorFunc func(ctx context.Context, req any) error
+
+// Validate implements Validator.
+func (f ValidatorFunc) Validate(ctx context.Context, req any) error {
+	return f(ctx, req)
+}
+
+// MethodValidator validates requests through their own methods, in order of
+// preference:
+//   - ValidateAll() error, reporting every violation (protoc-gen-validate)
+//   - Validate() error
+//   - Validate(ctx context.Context) error
+type MethodValidator struct{}
+
+// NewMethodValidator creates a MethodValidator.
+func NewMethodValidator() *MethodValidator {
+	return &MethodValidator{}
+}
+
+// Validate implements Validator.
+func (MethodValidator) Validate(ctx context.Context, req any) error {
+	switch r := req.(type) {
+	case interface{ ValidateAll() error }:
+		return Error(r.ValidateAll())
+	case interface{ Validate() error }:
+		return Error(r.Validate())
+	case interface{ Validate(context.Context) error }:
+		return Error(r.Validate(ctx))
+	}
+	return nil
+}
+
+// FieldError is a validation error about a single field. Errors generated by
+// protoc-gen-validate implement it; the violations of nested messages are
+// reported through Cause and prefixed with the parent's field.
+type FieldError interface {
+	error
+	Field() string
+	Reason() string
+}
+
+// Invalid creates the error returned for requests with field violations.
+func Invalid(violations ...errmap.FieldViolation) *errmap.Error {
+	return errmap.InvalidArgument(Message, violations...)
+}
+
+// Error converts a validation error into an *errmap.Error with
+// codes.InvalidArgument. Field errors and lists of them (AllErrors() []error
+// or errors.Join) become field violations; other errors become the message.
+// It returns nil for nil and *errmap.Error values unchanged.
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *errmap.Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	violations := violations(err)
+	if len(violations) == 0 {
+		return errmap.InvalidArgument(err.Error()).Wrap(err)
+	}
+	return Invalid(violations...).Wrap(err)
+}
+
+// violations collects the field violations of err.
+func violations(err error) []errmap.FieldViolation {
+	switch e := err.(type) {
+	case interface{ AllErrors() []error }:
+		return collect(e.AllErrors())
+	case interface{ Unwrap() []error }:
+		return collect(e.Unwrap())
+	case FieldError:
+		return fieldViolations(e)
+	}
+	return nil
+}
+
+// collect collects the field violations of errs; errors that are not about
+// fields are skipped.
+func collect(errs []error) []errmap.FieldViolation {
+	var out []errmap.FieldViolation
+	for _, err := range errs {
+		out = append(out, violations(err)...)
+	}
+	return out
+}
+
+// fieldViolations returns the violation of e, or the violations of its cause
+// prefixed with its field for errors about nested messages.
+func fieldViolations(e FieldError) []errmap.FieldViolation {
+	if c, ok := e.(interface{ Cause() error }); ok && c.Cause() != nil {
+		nested := violations(c.Cause())
+		for i := range nested {
+			nested[i].Field = e.Field() + "." + nested[i].Field
+		}
+		if len(nested) > 0 {
+			return nested
+		}
+	}
+	return []errmap.FieldViolation{{Field: e.Field(), Description: e.Reason()}}
+}
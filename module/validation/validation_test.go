@@ -0,0 +1,111 @@
+package validation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/errmap"
+	"github.com/things-kit/module/validation"
+	"google.golang.org/grpc/codes"
+)
+
+// fieldError mimics the errors generated by protoc-gen-validate
+type fieldError struct {
+	field  string
+	reason string
+	cause  error
+}
+
+func (e fieldError) Error() string  { return "invalid " + e.field + ": " + e.reason }
+func (e fieldError) Field() string  { return e.field }
+func (e fieldError) Reason() string { return e.reason }
+func (e fieldError) Cause() error   { return e.cause }
+
+// multiError mimics the multi errors generated by protoc-gen-validate
+type multiError []error
+
+func (m multiError) Error() string      { return "multiple errors" }
+func (m multiError) AllErrors() []error { return m }
+
+type pgvRequest struct{ err error }
+
+func (r pgvRequest) Validate() error {
+	return errors.New("Validate must not be used when ValidateAll exists")
+}
+func (r pgvRequest) ValidateAll() error { return r.err }
+
+type handwrittenRequest struct{ name string }
+
+func (r handwrittenRequest) Validate() error {
+	if r.name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+// TestMethodValidator verifies the conversion of validation errors into field violations
+func TestMethodValidator(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        any
+		message    string
+		violations []errmap.FieldViolation
+	}{
+		{
+			name:    "valid",
+			req:     handwrittenRequest{name: "ada"},
+			message: "",
+		},
+		{
+			name:    "no validation methods",
+			req:     struct{}{},
+			message: "",
+		},
+		{
+			name:    "plain error",
+			req:     handwrittenRequest{},
+			message: "name is required",
+		},
+		{
+			name: "all violations with nested messages",
+			req: pgvRequest{err: multiError{
+				fieldError{field: "email", reason: "value must be a valid email address"},
+				fieldError{field: "address", reason: "embedded message failed validation", cause: multiError{
+					fieldError{field: "city", reason: "value length must be at least 1 runes"},
+				}},
+			}},
+			message: validation.Message,
+			violations: []errmap.FieldViolation{
+				{Field: "email", Description: "value must be a valid email address"},
+				{Field: "address.city", Description: "value length must be at least 1 runes"},
+			},
+		},
+		{
+			name:    "joined errors",
+			req:     pgvRequest{err: errors.Join(fieldError{field: "age", reason: "must be positive"})},
+			message: validation.Message,
+			violations: []errmap.FieldViolation{
+				{Field: "age", Description: "must be positive"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validation.NewMethodValidator().Validate(context.Background(), tt.req)
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var e *errmap.Error
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, codes.InvalidArgument, e.Code)
+			assert.Equal(t, tt.message, e.Message)
+			assert.Equal(t, tt.violations, e.Violations)
+		})
+	}
+}
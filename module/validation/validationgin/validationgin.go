@@ -0,0 +1,224 @@
+// Package validationgin binds and validates the requests of the httpgin module.
+package validationgin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/things-kit/module/errmap"
+	"github.com/things-kit/module/errmap/errmapgin"
+	"github.com/things-kit/module/httpgin"
+	"github.com/things-kit/module/validation"
+	"go.uber.org/fx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Module makes BindWith run the application's validation.Validator after
+// binding, through a middleware installed at httpgin.OrderValidation. It
+// requires validation.Module or another validation.Validator.
+var Module = fx.Module("validationgin",
+	httpgin.AsGinMiddleware(func(v validation.Validator) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set(validatorKey, v)
+		}
+	}, httpgin.OrderValidation),
+)
+
+// validatorKey is the gin.Context key holding the application's validation.Validator.
+const validatorKey = "things-kit/validator"
+
+// BindJSON binds the JSON body into obj with BindWith.
+func BindJSON(c *gin.Context, obj any) bool {
+	return BindWith(c, obj, binding.JSON)
+}
+
+// BindQuery binds the query string into obj with BindWith.
+func BindQuery(c *gin.Context, obj any) bool {
+	return BindWith(c, obj, binding.Query)
+}
+
+// BindWith binds the request into obj and validates it. On failure it writes
+// a 400 response in the google.rpc.Status format of httpgin.WriteStatus,
+// listing the field violations in a google.rpc.BadRequest, and returns false.
+// Bodies cut off by httpgin.BodyLimit get a 413 instead.
+//
+// Example:
+//
+//	type createUserRequest struct {
+//	    Email string `json:"email" binding:"required,email"`
+//	    Age   int    `json:"age" binding:"gte=0"`
+//	}
+//
+//	func (h *UserHandler) create(c *gin.Context) {
+//	    var req createUserRequest
+//	    if !validationgin.BindJSON(c, &req) {
+//	        return // 400 {"code": 3, "message": "invalid request", "details": [...]}
+//	    }
+//	    ...
+//	}
+func BindWith(c *gin.Context, obj any, b binding.Binding) bool {
+	err := ShouldBindWith(c, obj, b)
+	if err == nil {
+		return true
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		httpgin.WriteStatus(c, http.StatusRequestEntityTooLarge,
+			status.Newf(codes.InvalidArgument, "request body exceeds %d bytes", tooLarge.Limit))
+		return false
+	}
+
+	errmapgin.WriteError(c, err)
+	return false
+}
+
+// ShouldBindWith binds the request into obj and validates it like BindWith,
+// but returns the error instead of writing a response. Errors are
+// *errmap.Error values with codes.InvalidArgument: binding rules (`binding`
+// struct tags) and decoding errors are reported as field violations named
+// after the request fields, followed by the checks of the validation.Validator
+// when Module is present.
+func ShouldBindWith(c *gin.Context, obj any, b binding.Binding) error {
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		return bindingError(err, obj, b)
+	}
+
+	if v, ok := c.Get(validatorKey); ok {
+		return validation.Error(v.(validation.Validator).Validate(c.Request.Context(), obj))
+	}
+	return nil
+}
+
+// bindingError converts an error returned by a gin binding into an *errmap.Error.
+func bindingError(err error, obj any, b binding.Binding) error {
+	var fieldErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var tooLarge *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &tooLarge):
+		return errmap.Newf(codes.InvalidArgument, "request body exceeds %d bytes", tooLarge.Limit).Wrap(err)
+	case errors.As(err, &fieldErrs):
+		tag := bindingTag(b)
+		violations := make([]errmap.FieldViolation, 0, len(fieldErrs))
+		for _, fe := range fieldErrs {
+			violations = append(violations, errmap.FieldViolation{
+				Field:       fieldPath(reflect.TypeOf(obj), fe.StructNamespace(), tag),
+				Description: describe(fe),
+			})
+		}
+		return validation.Invalid(violations...).Wrap(err)
+	case errors.As(err, &typeErr):
+		return validation.Invalid(errmap.FieldViolation{
+			Field:       typeErr.Field,
+			Description: fmt.Sprintf("must be of type %s, got %s", typeErr.Type, typeErr.Value),
+		}).Wrap(err)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return errmap.InvalidArgument("malformed request body").Wrap(err)
+	case errors.Is(err, io.EOF):
+		return errmap.InvalidArgument("request body is empty").Wrap(err)
+	}
+	return validation.Error(err)
+}
+
+// bindingTag returns the struct tag naming request fields for b.
+func bindingTag(b binding.Binding) string {
+	switch b {
+	case binding.Query, binding.Form, binding.FormPost, binding.FormMultipart:
+		return "form"
+	case binding.Header:
+		return "header"
+	case binding.XML:
+		return "xml"
+	case binding.YAML:
+		return "yaml"
+	case binding.TOML:
+		return "toml"
+	}
+	return "json"
+}
+
+// fieldPath converts a struct namespace like "createUserRequest.Address.Lines[0]"
+// into the request field path "address.lines[0]", using the names in tag.
+func fieldPath(t reflect.Type, namespace, tag string) string {
+	parts := strings.Split(namespace, ".")[1:]
+	for i, part := range parts {
+		name, index := part, ""
+		if j := strings.IndexByte(part, '['); j >= 0 {
+			name, index = part[:j], part[j:]
+		}
+
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			t = nil
+			continue
+		}
+
+		field, ok := t.FieldByName(name)
+		if !ok {
+			t = nil
+			continue
+		}
+		if tagged := strings.Split(field.Tag.Get(tag), ",")[0]; tagged != "" && tagged != "-" {
+			parts[i] = tagged + index
+		}
+
+		t = field.Type
+		if index != "" {
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if k := t.Kind(); k == reflect.Slice || k == reflect.Array || k == reflect.Map {
+				t = t.Elem()
+			}
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// describe returns a client-facing description of a failed binding rule.
+func describe(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "uuid":
+		return "must be a valid UUID"
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", fe.Param())
+	case "len":
+		return fmt.Sprintf("must have length %s", fe.Param())
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "lt":
+		return fmt.Sprintf("must be less than %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", fe.Param())
+	}
+	if fe.Param() != "" {
+		return fmt.Sprintf("must satisfy %s=%s", fe.Tag(), fe.Param())
+	}
+	return fmt.Sprintf("must satisfy %s", fe.Tag())
+}
@@ -0,0 +1,226 @@
+package validationgin_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/errmap"
+	"github.com/things-kit/module/httpgin"
+	"github.com/things-kit/module/log"
+	"github.com/things-kit/module/validation"
+	"github.com/things-kit/module/validation/validationgin"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+// nopLogger discards all log entries
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...log.Field)                           {}
+func (nopLogger) Error(string, error, ...log.Field)                   {}
+func (nopLogger) Debug(string, ...log.Field)                          {}
+func (nopLogger) Warn(string, ...log.Field)                           {}
+func (nopLogger) InfoC(context.Context, string, ...log.Field)         {}
+func (nopLogger) ErrorC(context.Context, string, error, ...log.Field) {}
+func (nopLogger) DebugC(context.Context, string, ...log.Field)        {}
+func (nopLogger) WarnC(context.Context, string, error, ...log.Field)  {}
+
+type address struct {
+	City string `json:"city" binding:"required"`
+}
+
+type createUserRequest struct {
+	Email     string    `json:"email" binding:"required,email"`
+	Age       int       `json:"age" binding:"gte=0"`
+	Addresses []address `json:"addresses" binding:"dive"`
+}
+
+// Validate is run by validation.Module after binding
+func (r *createUserRequest) Validate() error {
+	if strings.HasSuffix(r.Email, "@example.com") {
+		return errors.New("example.com addresses are not accepted")
+	}
+	if r.Email == "nil@things.dev" {
+		var err *errmap.Error // A typed nil is not a nil error
+		return err
+	}
+	return nil
+}
+
+type listUsersRequest struct {
+	PageSize int `form:"page_size" binding:"max=100"`
+}
+
+type usersHandler struct{}
+
+func (usersHandler) RegisterRoutes(engine *gin.Engine) {
+	engine.POST("/users", func(c *gin.Context) {
+		var req createUserRequest
+		if !validationgin.BindJSON(c, &req) {
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+	engine.GET("/users", func(c *gin.Context) {
+		var req listUsersRequest
+		if !validationgin.BindQuery(c, &req) {
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+}
+
+// TestBindWith verifies 400 responses with field violations named after request fields
+func TestBindWith(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	v := viper.New()
+	v.Set("http.port", 0)
+	v.Set("http.middleware.recovery", false) // Panics must fail the test
+
+	var server *httpgin.GinServer
+	app := fxtest.New(t,
+		fx.Supply(v),
+		fx.Provide(func() log.Logger { return nopLogger{} }),
+		httpgin.Module,
+		validation.Module,
+		validationgin.Module,
+		httpgin.AsGinHandler(func() usersHandler { return usersHandler{} }),
+		fx.Populate(&server),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		code       int
+		message    string
+		violations map[string]string
+	}{
+		{
+			name:   "valid",
+			method: http.MethodPost, target: "/users",
+			body: `{"email": "ada@things.dev", "age": 36, "addresses": [{"city": "London"}]}`,
+			code: http.StatusCreated,
+		},
+		{
+			name:   "binding rules",
+			method: http.MethodPost, target: "/users",
+			body:    `{"email": "ada", "age": -1, "addresses": [{"city": ""}]}`,
+			code:    http.StatusBadRequest,
+			message: validation.Message,
+			violations: map[string]string{
+				"email":             "must be a valid email address",
+				"age":               "must be greater than or equal to 0",
+				"addresses[0].city": "is required",
+			},
+		},
+		{
+			name:   "wrong type",
+			method: http.MethodPost, target: "/users",
+			body:       `{"email": "ada@things.dev", "age": "old"}`,
+			code:       http.StatusBadRequest,
+			message:    validation.Message,
+			violations: map[string]string{"age": "must be of type int, got string"},
+		},
+		{
+			name:   "malformed body",
+			method: http.MethodPost, target: "/users",
+			body:    `{"email":`,
+			code:    http.StatusBadRequest,
+			message: "malformed request body",
+		},
+		{
+			name:   "validator",
+			method: http.MethodPost, target: "/users",
+			body:    `{"email": "ada@example.com"}`,
+			code:    http.StatusBadRequest,
+			message: "example.com addresses are not accepted",
+		},
+		{
+			name:   "not an errmap error",
+			method: http.MethodPost, target: "/users",
+			body: `{"email": "nil@things.dev"}`,
+			code: http.StatusInternalServerError,
+		},
+		{
+			name:   "query",
+			method: http.MethodGet, target: "/users?page_size=500",
+			code:       http.StatusBadRequest,
+			message:    validation.Message,
+			violations: map[string]string{"page_size": "must be at most 100"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			server.Engine().ServeHTTP(w, req)
+
+			require.Equal(t, tt.code, w.Code, w.Body.String())
+			if tt.code != http.StatusBadRequest {
+				return
+			}
+
+			var body struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+				Details []struct {
+					FieldViolations []struct {
+						Field       string `json:"field"`
+						Description string `json:"description"`
+					} `json:"fieldViolations"`
+				} `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, 3, body.Code)
+			assert.Equal(t, tt.message, body.Message)
+
+			violations := map[string]string{}
+			for _, d := range body.Details {
+				for _, v := range d.FieldViolations {
+					violations[v.Field] = v.Description
+				}
+			}
+			if tt.violations == nil {
+				assert.Empty(t, violations)
+			} else {
+				assert.Equal(t, tt.violations, violations)
+			}
+		})
+	}
+}
+
+// TestBindWithBodyLimit verifies 413 responses for bodies cut off by httpgin.BodyLimit
+func TestBindWithBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(httpgin.BodyLimit(16))
+	engine.POST("/upload", func(c *gin.Context) {
+		var body map[string]any
+		if validationgin.BindJSON(c, &body) {
+			c.Status(http.StatusNoContent)
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", io.MultiReader(strings.NewReader(`{"name": "too large"}`)))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.JSONEq(t, `{"code": 3, "message": "request body exceeds 16 bytes"}`, w.Body.String())
+}
@@ -0,0 +1,52 @@
+// Package validationgrpc validates the request messages of the grpc module.
+package validationgrpc
+
+import (
+	"context"
+
+	grpcmodule "github.com/things-kit/module/grpc"
+	"github.com/things-kit/module/validation"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+)
+
+// Module chains the validation interceptors at grpcmodule.OrderValidation.
+// It requires validation.Module or another validation.Validator.
+var Module = fx.Module("validationgrpc",
+	grpcmodule.AsUnaryInterceptor(UnaryServerInterceptor, grpcmodule.OrderValidation),
+	grpcmodule.AsStreamInterceptor(StreamServerInterceptor, grpcmodule.OrderValidation),
+)
+
+// UnaryServerInterceptor rejects requests that fail validation with
+// codes.InvalidArgument and a google.rpc.BadRequest listing the field
+// violations, before the handler runs.
+func UnaryServerInterceptor(v validation.Validator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := v.Validate(ctx, req); err != nil {
+			return nil, validation.Error(err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the stream counterpart of UnaryServerInterceptor.
+// Every message received from the client is validated; an invalid message
+// fails the stream.
+func StreamServerInterceptor(v validation.Validator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: ss, validator: v})
+	}
+}
+
+// validatingStream validates the messages received on a server stream.
+type validatingStream struct {
+	grpc.ServerStream
+	validator validation.Validator
+}
+
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validation.Error(s.validator.Validate(s.Context(), m))
+}
@@ -0,0 +1,95 @@
+package validationgrpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/things-kit/module/validation"
+	"github.com/things-kit/module/validation/validationgrpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fieldError mimics the errors generated by protoc-gen-validate
+type fieldError struct{ field, reason string }
+
+func (e fieldError) Error() string  { return "invalid " + e.field + ": " + e.reason }
+func (e fieldError) Field() string  { return e.field }
+func (e fieldError) Reason() string { return e.reason }
+
+type createOrderRequest struct{ quantity int }
+
+func (r *createOrderRequest) Validate() error {
+	if r.quantity <= 0 {
+		return fieldError{field: "quantity", reason: "value must be greater than 0"}
+	}
+	return nil
+}
+
+// TestUnaryServerInterceptor verifies that invalid requests never reach the handler
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := validationgrpc.UnaryServerInterceptor(validation.NewMethodValidator())
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Create"}
+
+	called := false
+	handler := func(context.Context, any) (any, error) {
+		called = true
+		return "ok", nil
+	}
+
+	resp, err := interceptor(context.Background(), &createOrderRequest{quantity: 2}, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	assert.True(t, called)
+
+	called = false
+	_, err = interceptor(context.Background(), &createOrderRequest{}, info, handler)
+	assert.False(t, called)
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, validation.Message, st.Message())
+	require.Len(t, st.Details(), 1)
+	violations := st.Details()[0].(*errdetails.BadRequest).FieldViolations
+	require.Len(t, violations, 1)
+	assert.Equal(t, "quantity", violations[0].Field)
+	assert.Equal(t, "value must be greater than 0", violations[0].Description)
+}
+
+// TestStreamServerInterceptor verifies that received messages are validated
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := validationgrpc.StreamServerInterceptor(validation.NewMethodValidator())
+	stream := &recvStream{msgs: []int{1, 0}}
+
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/orders.v1.Orders/Import"},
+		func(_ any, ss grpc.ServerStream) error {
+			for {
+				if err := ss.RecvMsg(&createOrderRequest{}); err != nil {
+					return err
+				}
+			}
+		})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Empty(t, stream.msgs)
+}
+
+// recvStream is a server stream receiving order quantities
+type recvStream struct {
+	grpc.ServerStream
+	msgs []int
+}
+
+func (s *recvStream) Context() context.Context { return context.Background() }
+
+func (s *recvStream) RecvMsg(m any) error {
+	if len(s.msgs) == 0 {
+		return errors.New("EOF")
+	}
+	m.(*createOrderRequest).quantity, s.msgs = s.msgs[0], s.msgs[1:]
+	return nil
+}